@@ -0,0 +1,161 @@
+// cmd/assets.go
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// minCompressSize skips compressing files too small to benefit
+const minCompressSize = 1024
+
+// assetHandler serves static files with ETags and gzip or brotli
+// compression. Files are read on every request so edits show up when
+// serving from a directory; the compressed copy of each file is cached
+// until its ETag changes.
+type assetHandler struct {
+	files fs.FS
+	// cacheControl is sent with every file
+	cacheControl string
+
+	mu         sync.Mutex
+	compressed map[string]compressedAsset
+}
+
+// compressedAsset is a file's encoded body and the ETag of its content
+type compressedAsset struct {
+	etag string
+	body []byte
+}
+
+func newAssetHandler(files fs.FS, cacheControl string) *assetHandler {
+	return &assetHandler{files: files, cacheControl: cacheControl, compressed: map[string]compressedAsset{}}
+}
+
+func (h *assetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if name == "" || strings.HasSuffix(r.URL.Path, "/") {
+		name = path.Join(name, "index.html")
+	}
+	body, err := fs.ReadFile(h.files, name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := hex.EncodeToString(sum[:8])
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", contentType)
+	header.Set("Cache-Control", h.cacheControl)
+	header.Set("Vary", "Accept-Encoding")
+
+	if compressible(contentType) && len(body) >= minCompressSize {
+		switch acceptedEncoding(r.Header.Get("Accept-Encoding")) {
+		case "br":
+			body, etag = h.compress(name, etag, "br", body), etag+"-br"
+			header.Set("Content-Encoding", "br")
+		case "gzip":
+			body, etag = h.compress(name, etag, "gzip", body), etag+"-gz"
+			header.Set("Content-Encoding", "gzip")
+		}
+	}
+	etag = `"` + etag + `"`
+	header.Set("ETag", etag)
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Write(body)
+}
+
+// compress returns the file name's body encoded with encoding, reusing the
+// earlier result while the content's etag is the same. An edited file
+// replaces its entry, so the cache holds one copy per file and encoding.
+func (h *assetHandler) compress(name, etag, encoding string, body []byte) []byte {
+	key := name + "\x00" + encoding
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if cached, ok := h.compressed[key]; ok && cached.etag == etag {
+		return cached.body
+	}
+
+	var buf bytes.Buffer
+	switch encoding {
+	case "br":
+		bw := brotli.NewWriterLevel(&buf, brotli.BestCompression)
+		bw.Write(body)
+		bw.Close()
+	case "gzip":
+		gw, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+		gw.Write(body)
+		gw.Close()
+	}
+	h.compressed[key] = compressedAsset{etag: etag, body: buf.Bytes()}
+	return buf.Bytes()
+}
+
+func compressible(contentType string) bool {
+	return strings.HasPrefix(contentType, "text/") ||
+		strings.Contains(contentType, "javascript") ||
+		strings.Contains(contentType, "json") ||
+		strings.Contains(contentType, "svg")
+}
+
+// acceptedEncoding picks br over gzip from an Accept-Encoding header,
+// skipping encodings the client refuses with q=0
+func acceptedEncoding(header string) string {
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			q, _ = strconv.ParseFloat(v, 64)
+		}
+		accepted[strings.ToLower(strings.TrimSpace(name))] = q > 0
+	}
+	switch {
+	case accepted["br"]:
+		return "br"
+	case accepted["gzip"]:
+		return "gzip"
+	}
+	return ""
+}
+
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
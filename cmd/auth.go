@@ -0,0 +1,258 @@
+// cmd/auth.go
+package cmd
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sahil3982/vigil/internal/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	roleRead  = "read"
+	roleAdmin = "admin"
+)
+
+var roleRank = map[string]int{roleRead: 1, roleAdmin: 2}
+
+// adminEndpoints expose details about what runs on the host and need the
+// admin role. Everything else needs read, except publicEndpoints.
+var adminEndpoints = map[string]bool{
+	"/api/v1/processes":   true,
+	"/api/v1/containers":  true,
+	"/api/v1/units":       true,
+	"/api/v1/system/info": true,
+}
+
+// publicEndpoints stay reachable without credentials for health probes
+var publicEndpoints = map[string]bool{
+	"/api/v1/health": true,
+}
+
+func requiredRole(path string) string {
+	if adminEndpoints[path] {
+		return roleAdmin
+	}
+	return roleRead
+}
+
+// identity is who made a request and how they proved it
+type identity struct {
+	Name   string
+	Method string
+	Role   string
+}
+
+// authenticator checks bearer tokens, basic auth and verified client
+// certificates, in that order of precedence: client certificate first
+type authenticator struct {
+	cfg    config.Auth
+	tokens map[[32]byte]config.AuthToken
+	users  map[string]config.AuthUser
+	audit  *auditLog
+
+	// verified caches an HMAC of each password that passed bcrypt, so a
+	// dashboard polling every second does not pay for bcrypt every time.
+	// The key is random per process, so the cache is no help for
+	// guessing passwords offline; wrong passwords still go through bcrypt.
+	mu       sync.Mutex
+	cacheKey []byte
+	verified map[string][]byte
+}
+
+func newAuthenticator(cfg config.Auth, audit *auditLog) *authenticator {
+	a := &authenticator{
+		cfg:      cfg,
+		tokens:   map[[32]byte]config.AuthToken{},
+		users:    map[string]config.AuthUser{},
+		audit:    audit,
+		cacheKey: make([]byte, 32),
+		verified: map[string][]byte{},
+	}
+	rand.Read(a.cacheKey)
+	for _, t := range cfg.Tokens {
+		a.tokens[sha256.Sum256([]byte(t.Token))] = t
+	}
+	for _, u := range cfg.Users {
+		a.users[u.Name] = u
+	}
+	return a
+}
+
+// identify returns the caller's identity. ok is false when the request
+// carries invalid credentials, or none and anonymous access is off.
+func (a *authenticator) identify(r *http.Request) (id identity, ok bool) {
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		cn := r.TLS.VerifiedChains[0][0].Subject.CommonName
+		role := a.cfg.DefaultClientRole
+		for _, c := range a.cfg.ClientRoles {
+			if c.CommonName == cn {
+				role = c.Role
+			}
+		}
+		return identity{Name: cn, Method: "client_cert", Role: role}, true
+	}
+
+	if token, found := bearerToken(r); found {
+		// Hashing first keeps the map lookup independent of the token bytes
+		t, found := a.tokens[sha256.Sum256([]byte(token))]
+		return identity{Name: t.Name, Method: "token", Role: t.Role}, found
+	}
+	if name, password, found := r.BasicAuth(); found {
+		u, known := a.users[name]
+		if !known || !a.checkPassword(u, password) {
+			return identity{Name: name, Method: "basic"}, false
+		}
+		return identity{Name: name, Method: "basic", Role: u.Role}, true
+	}
+
+	if a.cfg.Anonymous != "" {
+		return identity{Method: "anonymous", Role: a.cfg.Anonymous}, true
+	}
+	return identity{Method: "none"}, false
+}
+
+// bearerToken returns the token of an Authorization header with the
+// Bearer scheme, in any case. EventSource cannot send headers, so the
+// stream also takes the token as an access_token query parameter.
+func bearerToken(r *http.Request) (string, bool) {
+	if scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " "); found && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token), true
+	}
+	if r.URL.Path == "/api/v1/stream" && r.URL.Query().Has("access_token") {
+		return r.URL.Query().Get("access_token"), true
+	}
+	return "", false
+}
+
+func (a *authenticator) checkPassword(u config.AuthUser, password string) bool {
+	mac := hmac.New(sha256.New, a.cacheKey)
+	mac.Write([]byte(password))
+	sum := mac.Sum(nil)
+	a.mu.Lock()
+	cached, hit := a.verified[u.Name]
+	a.mu.Unlock()
+	if hit && hmac.Equal(cached, sum) {
+		return true
+	}
+	if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) != nil {
+		return false
+	}
+	a.mu.Lock()
+	a.verified[u.Name] = sum
+	a.mu.Unlock()
+	return true
+}
+
+// middleware enforces roles and writes every API request to the audit log
+func (a *authenticator) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		id, ok := identity{Method: "public"}, true
+		if !publicEndpoints[r.URL.Path] {
+			id, ok = a.identify(r)
+		}
+
+		switch {
+		case !ok:
+			if len(a.cfg.Users) > 0 {
+				w.Header().Set("WWW-Authenticate", `Basic realm="vigil"`)
+			} else {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="vigil"`)
+			}
+			writeJSONError(sw, http.StatusUnauthorized, "authentication required")
+		case !publicEndpoints[r.URL.Path] && roleRank[id.Role] < roleRank[requiredRole(r.URL.Path)]:
+			writeJSONError(sw, http.StatusForbidden, fmt.Sprintf("%s requires the %s role", r.URL.Path, requiredRole(r.URL.Path)))
+		default:
+			next.ServeHTTP(sw, r)
+		}
+
+		if strings.HasPrefix(r.URL.Path, "/api/") {
+			a.audit.log(r, id, sw.status)
+		}
+	})
+}
+
+func writeJSONError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"error": msg})
+}
+
+// statusWriter records the status code of a response. Unwrap lets
+// http.ResponseController reach the underlying writer (e.g. to flush).
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusWriter) WriteHeader(code int) {
+	s.status = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusWriter) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// auditLog writes one JSON line per API request. A nil auditLog discards
+// entries.
+type auditLog struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+type auditEntry struct {
+	Time   time.Time `json:"time"`
+	Remote string    `json:"remote"`
+	User   string    `json:"user,omitempty"`
+	Auth   string    `json:"auth"`
+	Role   string    `json:"role,omitempty"`
+	Method string    `json:"method"`
+	Path   string    `json:"path"`
+	Status int       `json:"status"`
+}
+
+// openAuditLog appends to path, or writes to stderr for "-"
+func openAuditLog(path string) (*auditLog, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if path == "-" {
+		return &auditLog{w: os.Stderr}, nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &auditLog{w: f}, nil
+}
+
+func (l *auditLog) log(r *http.Request, id identity, status int) {
+	if l == nil {
+		return
+	}
+	entry := auditEntry{
+		Time:   time.Now().UTC(),
+		Remote: r.RemoteAddr,
+		User:   id.Name,
+		Auth:   id.Method,
+		Role:   id.Role,
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Status: status,
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	json.NewEncoder(l.w).Encode(entry)
+}
@@ -0,0 +1,146 @@
+// cmd/bench.go
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sahil3982/vigil/internal/format"
+	"github.com/sahil3982/vigil/internal/stats"
+)
+
+// significanceLevel is the p-value below which a change is reported as
+// significant
+const significanceLevel = 0.05
+
+var (
+	benchRuns    int
+	benchWarmup  int
+	benchCompare string
+)
+
+func benchMode() bool {
+	return benchRuns > 1 || benchWarmup > 0 || benchCompare != ""
+}
+
+// runBenchmark runs the command benchWarmup+benchRuns times and summarizes
+// the measured runs.
+func runBenchmark(args []string) format.BenchStat {
+	var baseline *format.BenchStat
+	if benchCompare != "" {
+		b, err := loadBench(benchCompare)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "✗ Failed to load %s: %v\n", benchCompare, err)
+			os.Exit(1)
+		}
+		baseline = &b
+	}
+
+	command := strings.Join(args, " ")
+	var results []format.ExecStat
+	for i := 0; i < benchWarmup; i++ {
+		runMeasured(args, fmt.Sprintf("Warmup %d/%d: %s", i+1, benchWarmup, command))
+	}
+	for i := 0; i < benchRuns; i++ {
+		stat := runMeasured(args, fmt.Sprintf("Run %d/%d: %s", i+1, benchRuns, command))
+		results = append(results, stat)
+	}
+
+	bench := summarizeBench(command, benchWarmup, results)
+	if baseline != nil {
+		bench.Comparison = compareBench(benchCompare, *baseline, bench)
+	}
+	return bench
+}
+
+// runMeasured profiles one run of a repeated command. It exits like exec
+// does when the run fails, so a broken command is not measured to
+// completion.
+func runMeasured(args []string, label string) format.ExecStat {
+	if !quiet {
+		fmt.Fprintf(os.Stderr, "▶ %s\n", label)
+	}
+	stat, err := profileCommand(args, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ Failed to start: %v\n", err)
+		os.Exit(startExitCode(err))
+	}
+	if code := execExitCode(stat); code != 0 {
+		fmt.Fprintf(os.Stderr, "✗ %s failed with exit code %d, aborting\n", strings.Join(args, " "), code)
+		os.Exit(code)
+	}
+	return stat
+}
+
+func summarizeBench(command string, warmup int, results []format.ExecStat) format.BenchStat {
+	elapsed, cpuSec, ram := benchSeries(results)
+	return format.BenchStat{
+		Command:        command,
+		Runs:           len(results),
+		Warmup:         warmup,
+		ElapsedSeconds: summarize(elapsed),
+		CPUSeconds:     summarize(cpuSec),
+		RAMPeakMB:      summarize(ram),
+		Outliers:       stats.Outliers(elapsed),
+		Results:        results,
+	}
+}
+
+func benchSeries(results []format.ExecStat) (elapsed, cpuSec, ram []float64) {
+	for _, r := range results {
+		elapsed = append(elapsed, r.ElapsedSeconds)
+		cpuSec = append(cpuSec, r.CPUSeconds)
+		ram = append(ram, r.RAMPeakMB)
+	}
+	return elapsed, cpuSec, ram
+}
+
+func summarize(xs []float64) format.Summary {
+	return format.Summary{
+		Mean:   stats.Mean(xs),
+		Median: stats.Median(xs),
+		StdDev: stats.StdDev(xs),
+		Min:    stats.Min(xs),
+		Max:    stats.Max(xs),
+	}
+}
+
+func compareBench(against string, base, curr format.BenchStat) *format.BenchComparison {
+	bElapsed, bCPU, bRAM := benchSeries(base.Results)
+	cElapsed, cCPU, cRAM := benchSeries(curr.Results)
+	return &format.BenchComparison{
+		Against:        against,
+		ElapsedSeconds: benchChange(base.ElapsedSeconds.Mean, curr.ElapsedSeconds.Mean, bElapsed, cElapsed),
+		CPUSeconds:     benchChange(base.CPUSeconds.Mean, curr.CPUSeconds.Mean, bCPU, cCPU),
+		RAMPeakMB:      benchChange(base.RAMPeakMB.Mean, curr.RAMPeakMB.Mean, bRAM, cRAM),
+	}
+}
+
+func benchChange(baseMean, currMean float64, base, curr []float64) format.BenchChange {
+	c := format.BenchChange{Baseline: baseMean, Current: currMean}
+	if baseMean > 0 {
+		c.Ratio = currMean / baseMean
+	}
+	if p, ok := stats.WelchTTest(base, curr); ok {
+		c.PValue = &p
+		c.Significant = p < significanceLevel
+	}
+	return c
+}
+
+func loadBench(path string) (format.BenchStat, error) {
+	var b format.BenchStat
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return b, err
+	}
+	if err := json.Unmarshal(data, &b); err != nil {
+		return b, err
+	}
+	if len(b.Results) == 0 {
+		return b, fmt.Errorf("no results found; expected the output of `vigil exec --runs N --json`")
+	}
+	return b, nil
+}
@@ -0,0 +1,113 @@
+// cmd/budget.go
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sahil3982/vigil/internal/format"
+	"github.com/sahil3982/vigil/internal/stats"
+)
+
+var (
+	baselinePath   string
+	toleranceFlag  string
+	updateBaseline bool
+)
+
+// parseTolerance accepts "10%" or "10" (both meaning ten percent)
+func parseTolerance(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "%"), 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid tolerance %q", s)
+	}
+	return v, nil
+}
+
+func newBaseline(results []format.ExecStat) format.Baseline {
+	elapsed, cpuSec, ram := benchSeries(results)
+	var cpuAvg []float64
+	for _, r := range results {
+		cpuAvg = append(cpuAvg, r.CPUAvgPercent)
+	}
+	return format.Baseline{
+		ExecStat: format.ExecStat{
+			Command:        results[0].Command,
+			ElapsedSeconds: stats.Mean(elapsed),
+			CPUSeconds:     stats.Mean(cpuSec),
+			CPUAvgPercent:  stats.Mean(cpuAvg),
+			RAMPeakMB:      stats.Mean(ram),
+		},
+		Samples:            len(results),
+		ElapsedVariance:    stats.Variance(elapsed),
+		CPUSecondsVariance: stats.Variance(cpuSec),
+		RAMPeakVariance:    stats.Variance(ram),
+	}
+}
+
+// checkBudget compares successful results against the baseline file, or
+// rewrites the file when --update-baseline is set. It exits on IO errors
+// since a CI guard must not silently pass.
+func checkBudget(results []format.ExecStat) *format.BudgetCheck {
+	tolerance, err := parseTolerance(toleranceFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ --tolerance: %v\n", err)
+		os.Exit(1)
+	}
+	check := &format.BudgetCheck{Baseline: baselinePath, TolerancePercent: tolerance}
+	current := newBaseline(results)
+
+	if updateBaseline {
+		data, _ := json.MarshalIndent(current, "", "  ")
+		if err := os.WriteFile(baselinePath, append(data, '\n'), 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "✗ Failed to write baseline: %v\n", err)
+			os.Exit(1)
+		}
+		check.Updated = true
+		return check
+	}
+
+	var base format.Baseline
+	data, err := os.ReadFile(baselinePath)
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "✗ Baseline %s does not exist; create it with --update-baseline\n", baselinePath)
+		os.Exit(1)
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &base)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ Failed to read baseline: %v\n", err)
+		os.Exit(1)
+	}
+
+	check.Metrics = []format.BudgetMetric{
+		budgetMetric("elapsed_seconds", base.ElapsedSeconds, base.ElapsedVariance, current.ElapsedSeconds, tolerance),
+		budgetMetric("cpu_seconds", base.CPUSeconds, base.CPUSecondsVariance, current.CPUSeconds, tolerance),
+		budgetMetric("ram_peak_mb", base.RAMPeakMB, base.RAMPeakVariance, current.RAMPeakMB, tolerance),
+	}
+	for _, m := range check.Metrics {
+		check.Regressed = check.Regressed || m.Regressed
+	}
+	return check
+}
+
+func budgetMetric(name string, base, variance, current, tolerance float64) format.BudgetMetric {
+	m := format.BudgetMetric{
+		Name:           name,
+		Baseline:       base,
+		BaselineStdDev: math.Sqrt(variance),
+		Current:        current,
+		Limit:          base * (1 + tolerance/100),
+	}
+	if base > 0 {
+		m.ChangePercent = (current/base - 1) * 100
+		m.Regressed = current > m.Limit
+	}
+	return m
+}
@@ -0,0 +1,104 @@
+// cmd/collect.go
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+// Exit codes for commands that read metrics, following sysexits(3)
+const (
+	exitPartial      = 2  // output was printed, but some sources failed
+	exitUnavailable  = 69 // the metric is not available on this system
+	exitNoPermission = 77 // vigil is not allowed to read the metric
+)
+
+// sourceError is a failure to read one metrics source, e.g. memory
+type sourceError struct {
+	Source string
+	Err    error
+}
+
+func (e *sourceError) Error() string {
+	return e.Source + ": " + e.Err.Error()
+}
+
+func (e *sourceError) Unwrap() error {
+	return e.Err
+}
+
+// collectExitCode tells a permission problem apart from a missing metric
+func collectExitCode(err error) int {
+	if errors.Is(err, fs.ErrPermission) {
+		return exitNoPermission
+	}
+	return exitUnavailable
+}
+
+// collectHint suggests what to check for a failed source, or returns ""
+func collectHint(source string, err error) string {
+	if source == "containers" {
+		switch {
+		case errors.Is(err, fs.ErrPermission):
+			return "add your user to the docker group, or use a rootless Podman socket with --socket"
+		case errors.Is(err, fs.ErrNotExist):
+			return "is Docker or Podman running? Point --socket at its API socket"
+		}
+		return ""
+	}
+	if source == "sensors" && errors.Is(err, fs.ErrNotExist) {
+		return "VMs usually have no sensors; in a container, mount the host's /sys and set HOST_SYS"
+	}
+	if source == "history" && errors.Is(err, fs.ErrNotExist) {
+		return "history.file is only written when vigil serve shuts down; an ndjson exporter is written as it runs"
+	}
+	if source == "cgroup" && errors.Is(err, fs.ErrNotExist) {
+		return "the mounted cgroupfs is not vigil's; run the container with its own cgroup namespace (e.g. docker --cgroupns=private)"
+	}
+	if source == "systemd" && errors.Is(err, fs.ErrNotExist) {
+		return "units are only visible on a systemd host, not from inside a container"
+	}
+	switch {
+	case errors.Is(err, fs.ErrPermission):
+		return "vigil cannot read /proc or /sys; in a container, check its mounts and seccomp profile"
+	case errors.Is(err, fs.ErrNotExist):
+		return "this metric is not available on this system (is /proc mounted?)"
+	}
+	return ""
+}
+
+// reportSourceErrors prints every failed source to stderr
+func reportSourceErrors(errs []*sourceError) {
+	for _, e := range errs {
+		fmt.Fprintf(os.Stderr, "✗ Could not read %s: %v\n", e.Source, e.Err)
+		if hint := collectHint(e.Source, e.Err); hint != "" {
+			fmt.Fprintf(os.Stderr, "  %s\n", hint)
+		}
+	}
+}
+
+// failSource reports a source the command cannot do without and exits
+func failSource(source string, err error) {
+	reportSourceErrors([]*sourceError{{Source: source, Err: err}})
+	os.Exit(collectExitCode(err))
+}
+
+// failOutput reports an error from the formatter, e.g. a bad --template
+func failOutput(err error) {
+	fmt.Fprintf(os.Stderr, "✗ Failed to write output: %v\n", err)
+	os.Exit(1)
+}
+
+// virtualMemory is mem.VirtualMemory, but an empty reading, which gopsutil
+// returns without an error when /proc/meminfo is unreadable, is an error
+func virtualMemory() (*mem.VirtualMemoryStat, error) {
+	v, err := mem.VirtualMemory()
+	if err == nil && v.Total == 0 {
+		err = errors.New("no memory information reported")
+	}
+	return v, err
+}
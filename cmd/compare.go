@@ -0,0 +1,126 @@
+// cmd/compare.go
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/sahil3982/vigil/internal/format"
+	"github.com/spf13/cobra"
+)
+
+var (
+	compareRuns      int
+	compareWarmup    int
+	compareAlternate bool
+)
+
+var compareCmd = &cobra.Command{
+	Use:   `compare -- "<command A>" -- "<command B>" [-- ...]`,
+	Short: "Profile several commands and compare time, CPU and RAM",
+	Run: func(cmd *cobra.Command, args []string) {
+		segments := splitCommands(args)
+		if len(segments) < 2 {
+			fmt.Fprintln(os.Stderr, `✗ Need at least two commands. Usage: vigil compare -- "make" -- "make -j8"`)
+			os.Exit(1)
+		}
+		if compareRuns < 1 || compareWarmup < 0 {
+			fmt.Fprintln(os.Stderr, "✗ --runs must be at least 1 and --warmup cannot be negative")
+			os.Exit(1)
+		}
+
+		names := make([]string, len(segments))
+		commands := make([][]string, len(segments))
+		for i, seg := range segments {
+			names[i] = strings.Join(seg, " ")
+			commands[i] = seg
+			if len(seg) == 1 && strings.ContainsAny(seg[0], " \t") {
+				commands[i] = shellCommand(seg[0])
+			}
+		}
+		label := func(kind string, run, total, i int) string {
+			return fmt.Sprintf("%s %d/%d [%c]: %s", kind, run+1, total, 'A'+i, names[i])
+		}
+
+		results := make([][]format.ExecStat, len(commands))
+		if compareAlternate {
+			// Interleave runs so drift (thermal throttling, caches, other
+			// load) affects every command equally
+			for r := 0; r < compareWarmup; r++ {
+				for i, c := range commands {
+					runMeasured(c, label("Warmup", r, compareWarmup, i))
+				}
+			}
+			for r := 0; r < compareRuns; r++ {
+				for i, c := range commands {
+					results[i] = append(results[i], runMeasured(c, label("Run", r, compareRuns, i)))
+				}
+			}
+		} else {
+			for i, c := range commands {
+				for r := 0; r < compareWarmup; r++ {
+					runMeasured(c, label("Warmup", r, compareWarmup, i))
+				}
+				for r := 0; r < compareRuns; r++ {
+					results[i] = append(results[i], runMeasured(c, label("Run", r, compareRuns, i)))
+				}
+			}
+		}
+
+		stat := format.CompareStat{
+			Runs:      compareRuns,
+			Warmup:    compareWarmup,
+			Alternate: compareAlternate,
+		}
+		for i, r := range results {
+			stat.Commands = append(stat.Commands, summarizeBench(names[i], compareWarmup, r))
+		}
+		for _, b := range stat.Commands[1:] {
+			stat.Comparisons = append(stat.Comparisons, *compareBench(names[0], stat.Commands[0], b))
+		}
+
+		f := newFormatter()
+		if err := f.Compare(os.Stdout, stat); err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+// splitCommands splits the arguments on "--". A command given as a single
+// argument containing spaces is later run through the shell, so both
+// `-- "make -j8"` and `-- make -j8` work.
+func splitCommands(args []string) [][]string {
+	var commands [][]string
+	var current []string
+	flush := func() {
+		if len(current) > 0 {
+			commands = append(commands, current)
+		}
+		current = nil
+	}
+	for _, a := range args {
+		if a == "--" {
+			flush()
+			continue
+		}
+		current = append(current, a)
+	}
+	flush()
+	return commands
+}
+
+func shellCommand(s string) []string {
+	if runtime.GOOS == "windows" {
+		return []string{"cmd", "/C", s}
+	}
+	return []string{"sh", "-c", s}
+}
+
+func init() {
+	compareCmd.Flags().IntVar(&compareRuns, "runs", 3, "Number of measured runs per command")
+	compareCmd.Flags().IntVar(&compareWarmup, "warmup", 0, "Number of discarded warmup runs per command")
+	compareCmd.Flags().BoolVar(&compareAlternate, "alternate", false, "Interleave the runs of all commands instead of running them in sequence")
+	rootCmd.AddCommand(compareCmd)
+}
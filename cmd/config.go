@@ -0,0 +1,169 @@
+// cmd/config.go
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/sahil3982/vigil/internal/config"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	configPath string
+	// cfg is the effective configuration: defaults, then the config file,
+	// then VIGIL_* environment variables. Flags are applied on top by each
+	// command.
+	cfg = config.Default()
+	// cfgSource is the file cfg was read from, or "" for none
+	cfgSource string
+)
+
+// loadConfig reads the config file named by --config or $VIGIL_CONFIG, or
+// the first one found in the default locations
+func loadConfig(cmd *cobra.Command) {
+	path := configPath
+	if path == "" {
+		path = os.Getenv("VIGIL_CONFIG")
+	}
+	if path == "" {
+		path = config.Find()
+	}
+
+	// config show and hash-password still work with a broken config, so
+	// it can be inspected and fixed
+	lenient := cmd == configShowCmd || cmd == configHashPasswordCmd
+	c, err := config.Load(path)
+	if err != nil {
+		where := path
+		if where == "" {
+			where = "environment"
+		}
+		if lenient {
+			fmt.Fprintf(os.Stderr, "⚠ Invalid config (%s):\n", where)
+		} else {
+			fmt.Fprintf(os.Stderr, "✗ Invalid config (%s):\n", where)
+		}
+		for _, line := range strings.Split(err.Error(), "\n") {
+			fmt.Fprintf(os.Stderr, "   %s\n", line)
+		}
+		if !lenient {
+			os.Exit(1)
+		}
+	}
+	for _, name := range config.UnknownEnv(os.Environ()) {
+		fmt.Fprintf(os.Stderr, "⚠ %s is not a config setting; see vigil config show for the names\n", name)
+	}
+	cfg, cfgSource = c, path
+
+	if !cmd.Flags().Changed("quiet") {
+		quiet = cfg.Output.Quiet
+	}
+	if !cmd.Flags().Changed("scope") {
+		scopeFlag = cfg.Scope
+	} else if !slices.Contains(config.Scopes, scopeFlag) {
+		fmt.Fprintf(os.Stderr, "✗ --scope must be one of %s\n", strings.Join(config.Scopes, ", "))
+		os.Exit(1)
+	}
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration file",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the config file and environment overrides",
+	Run: func(cmd *cobra.Command, args []string) {
+		// Loading already exited on any error
+		for _, w := range cfg.Warnings() {
+			fmt.Printf("⚠ %s\n", w)
+		}
+		if cfgSource == "" {
+			fmt.Println("✓ No config file found; defaults are valid")
+			fmt.Printf("  Searched: %s\n", strings.Join(config.Paths(), ", "))
+			return
+		}
+		fmt.Printf("✓ %s is valid\n", cfgSource)
+	},
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration (defaults, file and VIGIL_* overrides)",
+	Run: func(cmd *cobra.Command, args []string) {
+		source := cfgSource
+		if source == "" {
+			source = "defaults (no config file)"
+		}
+		fmt.Printf("# source: %s\n", source)
+
+		var overrides []string
+		for name, path := range config.EnvNames() {
+			if _, ok := os.LookupEnv(name); ok {
+				overrides = append(overrides, fmt.Sprintf("%s (%s)", name, path))
+			}
+		}
+		sort.Strings(overrides)
+		for _, o := range overrides {
+			fmt.Printf("# override: %s\n", o)
+		}
+
+		if err := config.Encode(os.Stdout, redacted(cfg)); err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+// redacted returns a copy of c with the bearer tokens masked, so the
+// output can be pasted into an issue. Password hashes are left as they are.
+func redacted(c config.Config) config.Config {
+	tokens := make([]config.AuthToken, len(c.Auth.Tokens))
+	for i, t := range c.Auth.Tokens {
+		t.Token = "***"
+		tokens[i] = t
+	}
+	c.Auth.Tokens = tokens
+	return c
+}
+
+var configHashPasswordCmd = &cobra.Command{
+	Use:   "hash-password",
+	Short: "Read a password from stdin and print its bcrypt hash for auth.users",
+	Run: func(cmd *cobra.Command, args []string) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		password := strings.TrimRight(line, "\r\n")
+		if password == "" {
+			fmt.Fprintf(os.Stderr, "✗ No password on stdin (%v)\n", err)
+			os.Exit(1)
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(hash))
+	},
+}
+
+// flagOr returns the flag's value when name was given on the command line
+// and the configured value otherwise
+func flagOr[T any](cmd *cobra.Command, name string, flag, configured T) T {
+	if cmd.Flags().Changed(name) {
+		return flag
+	}
+	return configured
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configHashPasswordCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,139 @@
+// cmd/containers.go
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sahil3982/vigil/internal/engine"
+	"github.com/sahil3982/vigil/internal/format"
+	"github.com/spf13/cobra"
+)
+
+// containerStatsWorkers bounds concurrent stats requests; each one takes
+// the engine about a second
+const containerStatsWorkers = 8
+
+var (
+	containersSocket string
+	containersAll    bool
+	containersSort   string
+)
+
+var containersCmd = &cobra.Command{
+	Use:   "containers",
+	Short: "Show CPU, memory, network and block IO of Docker or Podman containers",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !slices.Contains([]string{"cpu", "mem", "name"}, containersSort) {
+			fmt.Fprintf(os.Stderr, "✗ --sort must be cpu, mem or name\n")
+			os.Exit(1)
+		}
+		socket := flagOr(cmd, "socket", containersSocket, cfg.Containers.Socket)
+		if socket == "" {
+			socket = engine.DefaultSocket()
+		}
+		if socket == "" {
+			failSource("containers", fmt.Errorf("no Docker or Podman socket found: %w", fs.ErrNotExist))
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		list, errs, err := collectContainers(ctx, engine.New(socket), containersAll)
+		if err != nil {
+			failSource("containers", err)
+		}
+		sortContainers(list.Containers, containersSort)
+
+		f := newFormatter()
+		if err := format.Write(f, os.Stdout, list); err != nil {
+			failOutput(err)
+		}
+		if len(errs) > 0 {
+			reportSourceErrors(errs)
+			os.Exit(exitPartial)
+		}
+	},
+}
+
+// collectContainers lists containers and reads their stats concurrently.
+// Stopped containers (with all) are listed without stats. A container
+// whose stats fail, e.g. because it stopped meanwhile, is still listed and
+// its error returned; err is only set when the engine cannot be reached.
+func collectContainers(ctx context.Context, client *engine.Client, all bool) (format.ContainerList, []*sourceError, error) {
+	list := format.ContainerList{Time: time.Now().UTC(), Socket: client.Socket, Containers: []format.ContainerStat{}}
+	containers, err := client.Containers(ctx, all)
+	if err != nil {
+		return list, nil, err
+	}
+
+	stats := make([]format.ContainerStat, len(containers))
+	errs := make([]*sourceError, len(containers))
+	sem := make(chan struct{}, containerStatsWorkers)
+	var wg sync.WaitGroup
+	for i, c := range containers {
+		stats[i] = format.ContainerStat{ID: c.ID, Name: c.Name(), Image: c.Image, State: c.State, Labels: c.Labels}
+		if c.State != "running" {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			s, err := client.Stats(ctx, id)
+			if err != nil {
+				errs[i] = &sourceError{Source: "container " + stats[i].Name, Err: err}
+				return
+			}
+			fillContainerStat(&stats[i], s)
+		}(i, c.ID)
+	}
+	wg.Wait()
+	list.Containers = stats
+
+	var failed []*sourceError
+	for _, e := range errs {
+		if e != nil {
+			failed = append(failed, e)
+		}
+	}
+	return list, failed, nil
+}
+
+// sortContainers orders by cpu, mem or name; busiest first
+func sortContainers(cs []format.ContainerStat, by string) {
+	sort.SliceStable(cs, func(i, j int) bool {
+		switch by {
+		case "mem":
+			return cs[i].MemUsedBytes > cs[j].MemUsedBytes
+		case "name":
+			return cs[i].Name < cs[j].Name
+		}
+		return cs[i].CPUPercent > cs[j].CPUPercent
+	})
+}
+
+func fillContainerStat(c *format.ContainerStat, s engine.Stats) {
+	c.CPUPercent = s.CPUPercent()
+	c.MemUsedBytes = s.MemoryUsed()
+	c.MemLimitBytes = s.Memory.Limit
+	if c.MemLimitBytes > 0 {
+		c.MemPercent = float64(c.MemUsedBytes) / float64(c.MemLimitBytes) * 100
+	}
+	c.NetRxBytes, c.NetTxBytes = s.NetworkBytes()
+	c.BlockReadBytes, c.BlockWriteBytes = s.BlockBytes()
+	c.Pids = s.Pids.Current
+}
+
+func init() {
+	containersCmd.Flags().StringVar(&containersSocket, "socket", "", "Docker or Podman API socket (default: $DOCKER_HOST, $CONTAINER_HOST or the usual paths)")
+	containersCmd.Flags().BoolVarP(&containersAll, "all", "a", false, "Also list stopped containers")
+	containersCmd.Flags().StringVar(&containersSort, "sort", "cpu", "Sort by cpu, mem or name")
+	rootCmd.AddCommand(containersCmd)
+}
@@ -5,46 +5,37 @@ import (
 	"os"
 	"time"
 
-	"github.com/sahil3982/vigil/internal/format"
-
-	"github.com/shirou/gopsutil/v3/cpu"
 	"github.com/spf13/cobra"
 )
 
+var (
+	cpuPerCore bool
+	cpuWindow  time.Duration
+)
+
 var cpuCmd = &cobra.Command{
 	Use:   "cpu",
 	Short: "Show CPU usage percentage",
 	Run: func(cmd *cobra.Command, args []string) {
-		// Prime the pump (first call returns 0 on some systems)
-		_, _ = cpu.Percent(0, false)
-		time.Sleep(200 * time.Millisecond)
-
-		percent, err := cpu.Percent(300*time.Millisecond, false)
-		if err != nil || len(percent) == 0 {
-			// Fallback: try per-core and average
-			percent, err = cpu.Percent(500*time.Millisecond, true)
-			if err != nil || len(percent) == 0 {
-				os.Exit(1)
-			}
-			sum := 0.0
-			for _, p := range percent {
-				sum += p
-			}
-			percent = []float64{sum / float64(len(percent))}
+		usage, err := newCPUSampler(scopedCgroup()).Measure(cpuWindow)
+		if err != nil {
+			failSource("cpu", err)
 		}
 
-		stat := format.CPUStat{
-			Percent: percent[0],
-			Cores:   len(percent),
+		stat := cpuStat(usage)
+		if cpuPerCore {
+			stat.PerCore = usage.PerCore
 		}
 
-		f := format.New(jsonFlag, quiet)
+		f := newFormatter()
 		if err := f.CPU(os.Stdout, stat); err != nil {
-			os.Exit(1)
+			failOutput(err)
 		}
 	},
 }
 
 func init() {
+	cpuCmd.Flags().BoolVar(&cpuPerCore, "per-core", false, "also show usage of every core")
+	cpuCmd.Flags().DurationVar(&cpuWindow, "window", 500*time.Millisecond, "how long to measure usage over")
 	rootCmd.AddCommand(cpuCmd)
 }
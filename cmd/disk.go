@@ -2,13 +2,22 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"time"
 
+	"github.com/sahil3982/vigil/internal/forecast"
 	"github.com/sahil3982/vigil/internal/format"
 	"github.com/shirou/gopsutil/v3/disk"
 	"github.com/spf13/cobra"
 )
 
+var (
+	diskForecastFlag bool
+	diskWindow       time.Duration
+	diskHistoryFile  string
+)
+
 var diskCmd = &cobra.Command{
 	Use:   "disk",
 	Short: "Show disk usage (root or first partition)",
@@ -19,11 +28,11 @@ var diskCmd = &cobra.Command{
 			// Fall back to first partition
 			parts, err2 := disk.Partitions(false)
 			if err2 != nil || len(parts) == 0 {
-				os.Exit(1)
+				failSource("disk", err)
 			}
 			usage, err = disk.Usage(parts[0].Mountpoint)
 			if err != nil {
-				os.Exit(1)
+				failSource("disk", err)
 			}
 		}
 
@@ -35,13 +44,111 @@ var diskCmd = &cobra.Command{
 			UsedPercent: usage.UsedPercent,
 		}
 
-		f := format.New(jsonFlag, quiet)
+		if diskForecastFlag {
+			window := flagOr(cmd, "window", diskWindow, time.Duration(cfg.Forecast.Window))
+			if window <= 0 {
+				fmt.Fprintln(os.Stderr, "✗ --window must be positive")
+				os.Exit(1)
+			}
+			path := flagOr(cmd, "history", diskHistoryFile, storedHistory())
+			if path == "" {
+				fmt.Fprintln(os.Stderr, "✗ --forecast needs the history `vigil serve` stores: configure an ndjson exporter or history.file, or pass --history")
+				os.Exit(1)
+			}
+			points, err := readDiskPoints(path)
+			if err != nil {
+				failSource("history", err)
+			}
+			points = append(points, forecast.Point{Time: time.Now(), Used: float64(usage.Used)})
+			stat.Forecast = diskForecast(points, window, usage.Used, usage.Used+usage.Free)
+		}
+
+		f := newFormatter()
 		if err := f.Disk(os.Stdout, stat); err != nil {
-			os.Exit(1)
+			failOutput(err)
 		}
 	},
 }
 
+// storedHistory is where `vigil serve` keeps history on disk. An ndjson
+// exporter is preferred: it is written as points are recorded, while
+// history.file is only written on shutdown.
+func storedHistory() string {
+	for _, e := range cfg.Exporters {
+		if e.Type == "ndjson" {
+			return e.Path
+		}
+	}
+	return cfg.History.File
+}
+
+// readDiskPoints reads the root filesystem's usage from a history file.
+// Only the readings are kept, so a long export fits in memory.
+func readDiskPoints(path string) ([]forecast.Point, error) {
+	var points []forecast.Point
+	err := eachHistoryPoint(path, func(point map[string]interface{}) {
+		points = append(points, diskPoints([]map[string]interface{}{point})...)
+	})
+	return points, err
+}
+
+// diskPoints reads the root filesystem's usage from history points, either
+// as `vigil serve` keeps them or as decoded from a file. Points whose disk
+// source had failed are skipped.
+func diskPoints(history []map[string]interface{}) []forecast.Point {
+	points := make([]forecast.Point, 0, len(history))
+	for _, h := range history {
+		section, _ := h["disk"].(map[string]interface{})
+		var used float64
+		switch v := section["used"].(type) {
+		case uint64:
+			used = float64(v)
+		case float64:
+			used = v
+		default:
+			continue
+		}
+		var at time.Time
+		switch t := h["timestamp"].(type) {
+		case time.Time:
+			at = t
+		case string:
+			at, _ = time.Parse(time.RFC3339Nano, t)
+		}
+		if at.IsZero() {
+			continue
+		}
+		points = append(points, forecast.Point{Time: at, Used: used})
+	}
+	return points
+}
+
+// diskForecast fits a trend to the points within window, which should end
+// with the current reading. Full means used reaching capacity, the space
+// available to unprivileged users (used plus free).
+func diskForecast(points []forecast.Point, window time.Duration, used, capacity uint64) *format.DiskForecast {
+	f := &format.DiskForecast{WindowSeconds: window.Seconds()}
+	trend, err := forecast.Linear(points, window)
+	f.Points = trend.Points
+	if err != nil {
+		return f
+	}
+	f.SpanSeconds = trend.Span.Seconds()
+	f.GrowthBytesPerDay = trend.BytesPerDay
+	if days, ok := trend.DaysUntilFull(float64(used), float64(capacity)); ok {
+		f.DaysUntilFull = &days
+		// Far enough out, the date would overflow a time.Duration
+		if days < 100*365 {
+			at := time.Now().Add(time.Duration(days * 24 * float64(time.Hour))).UTC()
+			f.FullAt = &at
+		}
+	}
+	return f
+}
+
 func init() {
+	diskCmd.Flags().BoolVar(&diskForecastFlag, "forecast", false, "Predict when the disk fills up from the history vigil serve stores")
+	diskCmd.Flags().DurationVar(&diskWindow, "window", 24*time.Hour, "How much history the forecast fits its trend to")
+	diskCmd.Flags().StringVar(&diskHistoryFile, "history", "", "History file or ndjson export to forecast from (defaults to the configured one)")
 	rootCmd.AddCommand(diskCmd)
-}
\ No newline at end of file
+}
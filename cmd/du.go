@@ -0,0 +1,142 @@
+// cmd/du.go
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sahil3982/vigil/internal/du"
+	"github.com/sahil3982/vigil/internal/format"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/spf13/cobra"
+)
+
+var (
+	duTop      int
+	duWorkers  int
+	duExclude  []string
+	duCross    bool
+	duApparent bool
+)
+
+var duCmd = &cobra.Command{
+	Use:   "du [path]",
+	Short: "Show the largest directories and files below a path (default .)",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		root := "."
+		if len(args) > 0 {
+			root = args[0]
+		}
+		if duTop < 0 || duWorkers < 0 {
+			fmt.Fprintln(os.Stderr, "✗ --top and --workers cannot be negative")
+			os.Exit(1)
+		}
+		for _, pattern := range duExclude {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				fmt.Fprintf(os.Stderr, "✗ --exclude %q is not a valid pattern\n", pattern)
+				os.Exit(1)
+			}
+		}
+		if abs, err := filepath.Abs(root); err == nil {
+			root = abs
+		}
+
+		start := time.Now()
+		result, err := du.Walk(root, du.Options{
+			Workers:          duWorkers,
+			CrossFilesystems: duCross,
+			Exclude:          duExclude,
+			Top:              duTop,
+			ByApparent:       duApparent,
+		})
+		if errors.Is(err, fs.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+			os.Exit(1)
+		}
+		if err != nil {
+			failSource("du", err)
+		}
+		stat := duStat(root, result, time.Since(start))
+		// The filesystem's own usage is context, not something du needs
+		if usage, err := disk.Usage(mountpointOf(root)); err == nil {
+			stat.Disk = &format.DiskStat{
+				Path:        usage.Path,
+				TotalBytes:  usage.Total,
+				UsedBytes:   usage.Used,
+				FreeBytes:   usage.Free,
+				UsedPercent: usage.UsedPercent,
+			}
+		}
+
+		f := newFormatter()
+		if err := format.Write(f, os.Stdout, stat); err != nil {
+			failOutput(err)
+		}
+		if result.Unreadable > 0 {
+			fmt.Fprintf(os.Stderr, "✗ %d entries could not be read, so sizes are undercounted (first: %v)\n", result.Unreadable, result.Err)
+			os.Exit(exitPartial)
+		}
+	},
+}
+
+// mountpointOf returns the mount point of the filesystem path is on, or
+// path itself when the mounts cannot be read
+func mountpointOf(path string) string {
+	parts, err := disk.Partitions(true)
+	if err != nil {
+		return path
+	}
+	best := ""
+	for _, p := range parts {
+		mp := p.Mountpoint
+		under := path == mp || strings.HasPrefix(path, strings.TrimSuffix(mp, string(filepath.Separator))+string(filepath.Separator))
+		if under && len(mp) > len(best) {
+			best = mp
+		}
+	}
+	if best == "" {
+		return path
+	}
+	return best
+}
+
+func duStat(root string, r du.Result, elapsed time.Duration) format.DuStat {
+	stat := format.DuStat{
+		Path:           root,
+		ApparentBytes:  r.Total.ApparentBytes,
+		AllocatedBytes: r.Total.AllocatedBytes,
+		Files:          r.Total.Files,
+		RankedBy:       "allocated",
+		ElapsedSeconds: elapsed.Seconds(),
+		Unreadable:     r.Unreadable,
+		Dirs:           duEntries(r.Dirs),
+		TopFiles:       duEntries(r.Files),
+	}
+	if duApparent {
+		stat.RankedBy = "apparent"
+	}
+	return stat
+}
+
+func duEntries(entries []du.Entry) []format.DuEntry {
+	out := make([]format.DuEntry, len(entries))
+	for i, e := range entries {
+		out[i] = format.DuEntry{Path: e.Path, ApparentBytes: e.ApparentBytes, AllocatedBytes: e.AllocatedBytes, Files: e.Files}
+	}
+	return out
+}
+
+func init() {
+	duCmd.Flags().IntVar(&duTop, "top", 10, "Number of directories and of files to list")
+	duCmd.Flags().IntVar(&duWorkers, "workers", 8, "Directories read in parallel (0 for one per CPU)")
+	duCmd.Flags().StringArrayVar(&duExclude, "exclude", nil, "Skip entries whose name or path matches this glob (e.g. node_modules, '*.iso'); repeatable")
+	duCmd.Flags().BoolVar(&duCross, "cross-filesystems", false, "Also descend into other filesystems mounted below the path")
+	duCmd.Flags().BoolVar(&duApparent, "apparent", false, "Rank by apparent size instead of the space allocated on disk")
+	rootCmd.AddCommand(duCmd)
+}
@@ -4,15 +4,22 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"os/exec"
+	"strings"
 	"time"
 
 	"github.com/sahil3982/vigil/internal/format"
-	"github.com/shirou/gopsutil/v3/cpu"
-	"github.com/shirou/gopsutil/v3/process"
+	"github.com/sahil3982/vigil/internal/record"
 	"github.com/spf13/cobra"
 )
 
+var (
+	recordPath     string
+	sampleInterval time.Duration
+	maxRSSFlag     string
+	limits         execLimits
+	foreground     bool
+)
+
 var execCmd = &cobra.Command{
 	Use:   "exec -- <command> [args...]",
 	Short: "Run and profile a command (CPU, RAM, duration)",
@@ -21,90 +28,122 @@ var execCmd = &cobra.Command{
 			fmt.Fprintln(os.Stderr, "✗ No command provided. Usage: vigil exec -- go build")
 			os.Exit(1)
 		}
+		if sampleInterval <= 0 {
+			fmt.Fprintln(os.Stderr, "✗ --sample-interval must be positive")
+			os.Exit(1)
+		}
 
-		start := time.Now()
+		if maxRSSFlag != "" {
+			size, err := parseSize(maxRSSFlag)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "✗ --max-rss: %v\n", err)
+				os.Exit(1)
+			}
+			limits.maxRSS = size
+		}
+		if limits.enforce && limits.maxCPUSeconds <= 0 {
+			// RLIMIT_AS counts reserved address space, not RSS, so memory
+			// and wall time are only ever checked by sampling
+			fmt.Fprintln(os.Stderr, "✗ --enforce only applies to --max-cpu-seconds; --max-rss and --max-time are checked every sample")
+			os.Exit(1)
+		}
 
-		c := exec.Command(args[0], args[1:]...)
-		c.Stdin = os.Stdin
-		c.Stdout = os.Stdout
-		c.Stderr = os.Stderr
+		if updateBaseline && baselinePath == "" {
+			fmt.Fprintln(os.Stderr, "✗ --update-baseline requires --baseline")
+			os.Exit(1)
+		}
+
+		if benchMode() {
+			if recordPath != "" {
+				fmt.Fprintln(os.Stderr, "✗ --record cannot be combined with --runs, --warmup or --compare")
+				os.Exit(1)
+			}
+			if benchRuns < 1 || benchWarmup < 0 {
+				fmt.Fprintln(os.Stderr, "✗ --runs must be at least 1 and --warmup cannot be negative")
+				os.Exit(1)
+			}
+			bench := runBenchmark(args)
+			if baselinePath != "" {
+				bench.Budget = checkBudget(bench.Results)
+			}
+
+			f := newFormatter()
+			if err := f.Bench(os.Stdout, bench); err != nil {
+				os.Exit(1)
+			}
+			if bench.Budget != nil && bench.Budget.Regressed {
+				os.Exit(exitRegression)
+			}
+			return
+		}
+
+		var rec record.Writer
+		var recErr error
+		var onSample func(format.ExecSample)
+		if recordPath != "" {
+			var err error
+			rec, err = record.Create(recordPath)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "✗ Failed to create recording: %v\n", err)
+				os.Exit(1)
+			}
+			onSample = func(s format.ExecSample) {
+				if recErr == nil {
+					recErr = rec.Write(s)
+				}
+			}
+		}
 
 		if !quiet {
-			fmt.Fprintf(os.Stderr, "▶ Running: %s\n", c.String())
+			fmt.Fprintf(os.Stderr, "▶ Running: %s\n", strings.Join(args, " "))
 		}
 
-		err := c.Start()
+		stat, err := profileCommand(args, onSample)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "✗ Failed to start: %v\n", err)
-			os.Exit(1)
+			os.Exit(startExitCode(err))
 		}
 
-		// Monitoring
-		var maxRAM float64
-		var cpuSum float64
-		var cpuSamples int
-
-		ticker := time.NewTicker(200 * time.Millisecond)
-		done := make(chan bool, 1)
-
-		go func() {
-			for {
-				select {
-				case <-done:
-					return
-				case <-ticker.C:
-					// Memory
-					p, err := process.NewProcess(int32(c.Process.Pid))
-					if err == nil {
-						memInfo, _ := p.MemoryInfo()
-						if memInfo != nil {
-							mb := float64(memInfo.RSS) / (1024 * 1024)
-							if mb > maxRAM {
-								maxRAM = mb
-							}
-						}
-					}
-
-					// CPU
-					perc, _ := cpu.Percent(0, false)
-					if len(perc) > 0 {
-						cpuSum += perc[0]
-						cpuSamples++
-					}
-				}
+		if rec != nil {
+			if err := rec.Close(); err != nil && recErr == nil {
+				recErr = err
+			}
+			if recErr != nil {
+				fmt.Fprintf(os.Stderr, "✗ Recording to %s failed: %v\n", recordPath, recErr)
 			}
-		}()
-
-		err = c.Wait()
-		ticker.Stop()
-		done <- true
-		close(done)
-
-		elapsed := time.Since(start).Seconds()
-		avgCPU := 0.0
-		if cpuSamples > 0 {
-			avgCPU = cpuSum / float64(cpuSamples)
 		}
 
-		stat := format.ExecStat{
-			Command:        c.String(),
-			ExitCode:       c.ProcessState.ExitCode(),
-			ElapsedSeconds: elapsed,
-			CPUAvgPercent:  avgCPU,
-			RAMPeakMB:      maxRAM,
+		// Failed runs are never compared against, or saved as, a baseline
+		if baselinePath != "" && execExitCode(stat) == 0 {
+			stat.Budget = checkBudget([]format.ExecStat{stat})
 		}
 
-		f := format.New(jsonFlag, quiet)
+		f := newFormatter()
 		if err := f.Exec(os.Stdout, stat); err != nil {
 			os.Exit(1)
 		}
 
-		if stat.ExitCode != 0 && !jsonFlag && !quiet {
-			os.Exit(stat.ExitCode)
+		if stat.Budget != nil && stat.Budget.Regressed {
+			os.Exit(exitRegression)
 		}
+		os.Exit(execExitCode(stat))
 	},
 }
 
 func init() {
+	execCmd.Flags().StringVar(&recordPath, "record", "", "Record every sample to a file (.csv for CSV, otherwise NDJSON)")
+	execCmd.Flags().DurationVar(&sampleInterval, "sample-interval", 200*time.Millisecond, "Interval between resource samples")
+	execCmd.Flags().StringVar(&maxRSSFlag, "max-rss", "", "Stop the command when the RSS of its process tree exceeds this size (e.g. 512M, 2G)")
+	execCmd.Flags().DurationVar(&limits.maxTime, "max-time", 0, "Stop the command after this wall time (e.g. 10m)")
+	execCmd.Flags().Float64Var(&limits.maxCPUSeconds, "max-cpu-seconds", 0, "Stop the command after its process tree used this much CPU time")
+	execCmd.Flags().DurationVar(&limits.grace, "kill-grace", 5*time.Second, "Time between SIGTERM and SIGKILL when a limit is exceeded")
+	execCmd.Flags().BoolVar(&foreground, "foreground", false, "Keep the command in vigil's process group; signals and limit kills then only reach the command itself")
+	execCmd.Flags().BoolVar(&limits.enforce, "enforce", false, "Also enforce --max-cpu-seconds in the kernel via RLIMIT_CPU (Linux only; memory is not enforced)")
+	execCmd.Flags().IntVar(&benchRuns, "runs", 1, "Run the command N times and report statistics")
+	execCmd.Flags().IntVar(&benchWarmup, "warmup", 0, "Number of discarded warmup runs before measuring")
+	execCmd.Flags().StringVar(&benchCompare, "compare", "", "Compare against an earlier benchmark saved with --runs N --json")
+	execCmd.Flags().StringVar(&baselinePath, "baseline", "", "Fail when the result regresses against this baseline file")
+	execCmd.Flags().StringVar(&toleranceFlag, "tolerance", "10%", "Allowed regression against the baseline")
+	execCmd.Flags().BoolVar(&updateBaseline, "update-baseline", false, "Write the result to the --baseline file instead of comparing")
 	rootCmd.AddCommand(execCmd)
-}
\ No newline at end of file
+}
@@ -0,0 +1,85 @@
+// cmd/io.go
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/sahil3982/vigil/internal/diskio"
+	"github.com/sahil3982/vigil/internal/format"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ioInterval time.Duration
+	ioDevices  []string
+	ioAll      bool
+)
+
+var ioCmd = &cobra.Command{
+	Use:   "io",
+	Short: "Show IOPS, throughput, latency, queue depth and utilization per disk",
+	Run: func(cmd *cobra.Command, args []string) {
+		if ioInterval <= 0 {
+			fmt.Fprintln(os.Stderr, "✗ --interval must be positive")
+			os.Exit(1)
+		}
+		for _, pattern := range ioDevices {
+			if _, err := path.Match(pattern, ""); err != nil {
+				fmt.Fprintf(os.Stderr, "✗ --device %q is not a valid pattern\n", pattern)
+				os.Exit(1)
+			}
+		}
+
+		devices, elapsed, err := diskio.New().Measure(ioInterval)
+		if err != nil {
+			failSource("io", err)
+		}
+		f := newFormatter()
+		if err := format.Write(f, os.Stdout, diskIOStat(ioFilter(ioDevices, ioAll).Apply(devices), elapsed)); err != nil {
+			failOutput(err)
+		}
+	},
+}
+
+// ioFilter keeps the devices matching include, or every device but those
+// in io.exclude (loop and ram devices by default) unless all is set
+func ioFilter(include []string, all bool) diskio.Filter {
+	if len(include) > 0 || all {
+		return diskio.Filter{Include: include}
+	}
+	return diskio.Filter{Exclude: cfg.IO.Exclude}
+}
+
+func diskIOStat(devices []diskio.Device, elapsed time.Duration) format.DiskIOStat {
+	stat := format.DiskIOStat{
+		Time:            time.Now().UTC(),
+		IntervalSeconds: elapsed.Seconds(),
+		Devices:         make([]format.DeviceIOStat, len(devices)),
+	}
+	for i, d := range devices {
+		stat.Devices[i] = format.DeviceIOStat{
+			Device:           d.Name,
+			ReadIOPS:         d.ReadIOPS,
+			WriteIOPS:        d.WriteIOPS,
+			ReadBytesPerSec:  d.ReadBytesPerSec,
+			WriteBytesPerSec: d.WriteBytesPerSec,
+			ReadLatencyMs:    d.ReadLatencyMs,
+			WriteLatencyMs:   d.WriteLatencyMs,
+			QueueDepth:       d.QueueDepth,
+			InFlight:         d.InFlight,
+			UtilPercent:      d.UtilPercent,
+			WeightedIOMs:     d.WeightedIOMs,
+		}
+	}
+	return stat
+}
+
+func init() {
+	ioCmd.Flags().DurationVar(&ioInterval, "interval", time.Second, "Interval over which rates are measured")
+	ioCmd.Flags().StringArrayVar(&ioDevices, "device", nil, "Only show devices matching this glob (e.g. sda, 'nvme*'); repeatable")
+	ioCmd.Flags().BoolVar(&ioAll, "all", false, "Also show devices in io.exclude (loop and ram devices by default)")
+	rootCmd.AddCommand(ioCmd)
+}
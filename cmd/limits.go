@@ -0,0 +1,60 @@
+// cmd/limits.go
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/sahil3982/vigil/internal/format"
+)
+
+type execLimits struct {
+	maxRSS        uint64
+	maxTime       time.Duration
+	maxCPUSeconds float64
+	grace         time.Duration
+	enforce       bool
+}
+
+func (l execLimits) any() bool {
+	return l.maxRSS > 0 || l.maxTime > 0 || l.maxCPUSeconds > 0
+}
+
+// check compares a sample against the limits and returns the first breach
+func (l execLimits) check(s format.ExecSample, cpuSeconds float64) *format.LimitBreach {
+	if l.maxRSS > 0 && s.RSSBytes > l.maxRSS {
+		return &format.LimitBreach{
+			Limit:     "rss",
+			Threshold: float64(l.maxRSS) / (1024 * 1024),
+			Value:     float64(s.RSSBytes) / (1024 * 1024),
+		}
+	}
+	if l.maxTime > 0 && s.ElapsedSeconds > l.maxTime.Seconds() {
+		return &format.LimitBreach{
+			Limit:     "time",
+			Threshold: l.maxTime.Seconds(),
+			Value:     s.ElapsedSeconds,
+		}
+	}
+	if l.maxCPUSeconds > 0 && cpuSeconds > l.maxCPUSeconds {
+		return &format.LimitBreach{
+			Limit:     "cpu_seconds",
+			Threshold: l.maxCPUSeconds,
+			Value:     cpuSeconds,
+		}
+	}
+	return nil
+}
+
+// terminate asks the process to stop with SIGTERM and kills it if it is
+// still around after the grace period. The returned timer must be stopped
+// once the process has exited.
+func terminate(p *os.Process, group bool, grace time.Duration) *time.Timer {
+	if err := terminateProcess(p, group); err != nil {
+		killProcess(p, group)
+		return time.NewTimer(0)
+	}
+	return time.AfterFunc(grace, func() {
+		killProcess(p, group)
+	})
+}
@@ -0,0 +1,60 @@
+//go:build linux
+
+// cmd/limits_linux.go
+package cmd
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/sahil3982/vigil/internal/format"
+	"golang.org/x/sys/unix"
+)
+
+// applyKernelLimits sets RLIMIT_CPU on the child so the kernel stops it even
+// between samples. The soft limit sends SIGXCPU, the hard limit SIGKILL.
+// The limit is per process: children inherit it but each has its own
+// budget. Memory is not enforced; RLIMIT_AS caps address space, which
+// runtimes that reserve memory up front exceed long before their RSS does.
+func applyKernelLimits(pid int, l execLimits) error {
+	if l.maxCPUSeconds <= 0 {
+		return nil
+	}
+	soft := softCPULimit(l)
+	hard := soft + uint64(l.grace.Seconds()+0.999)
+	return unix.Prlimit(pid, unix.RLIMIT_CPU, &unix.Rlimit{Cur: soft, Max: hard}, nil)
+}
+
+// kernelBreach reports a breach that was enforced by the kernel rather than
+// by vigil's own monitoring: SIGXCPU at the soft limit, or SIGKILL at the
+// hard one when the command ignored SIGXCPU. A SIGKILL before the soft
+// limit came from someone else.
+func kernelBreach(state *os.ProcessState, l execLimits) *format.LimitBreach {
+	if !l.enforce || l.maxCPUSeconds <= 0 {
+		return nil
+	}
+	ws, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !ws.Signaled() {
+		return nil
+	}
+	used := (state.UserTime() + state.SystemTime()).Seconds()
+	switch ws.Signal() {
+	case syscall.SIGXCPU:
+	case syscall.SIGKILL:
+		if used < float64(softCPULimit(l)) {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return &format.LimitBreach{
+		Limit:     "cpu_seconds",
+		Threshold: l.maxCPUSeconds,
+		Value:     used,
+	}
+}
+
+// softCPULimit is RLIMIT_CPU's soft limit, in whole seconds
+func softCPULimit(l execLimits) uint64 {
+	return uint64(l.maxCPUSeconds + 0.999)
+}
@@ -0,0 +1,19 @@
+//go:build !linux
+
+// cmd/limits_other.go
+package cmd
+
+import (
+	"errors"
+	"os"
+
+	"github.com/sahil3982/vigil/internal/format"
+)
+
+func applyKernelLimits(pid int, l execLimits) error {
+	return errors.New("--enforce is only supported on Linux")
+}
+
+func kernelBreach(state *os.ProcessState, l execLimits) *format.LimitBreach {
+	return nil
+}
@@ -4,8 +4,6 @@ package cmd
 import (
 	"os"
 
-	"github.com/sahil3982/vigil/internal/format"
-	"github.com/shirou/gopsutil/v3/mem"
 	"github.com/spf13/cobra"
 )
 
@@ -13,22 +11,14 @@ var memCmd = &cobra.Command{
 	Use:   "mem",
 	Short: "Show memory (RAM) usage",
 	Run: func(cmd *cobra.Command, args []string) {
-		v, err := mem.VirtualMemory()
+		stat, err := memStat(scopedCgroup())
 		if err != nil {
-			os.Exit(1)
+			failSource("memory", err)
 		}
 
-		stat := format.MemStat{
-			TotalBytes:     v.Total,
-			UsedBytes:      v.Used,
-			FreeBytes:      v.Free,
-			AvailableBytes: v.Available,
-			UsedPercent:    v.UsedPercent,
-		}
-
-		f := format.New(jsonFlag, quiet)
+		f := newFormatter()
 		if err := f.Mem(os.Stdout, stat); err != nil {
-			os.Exit(1)
+			failOutput(err)
 		}
 	},
 }
@@ -0,0 +1,58 @@
+//go:build linux
+
+// cmd/netns_linux.go
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type netCounters struct {
+	rx, tx uint64
+}
+
+// readNetCounters sums the non-loopback interface counters of the network
+// namespace pid lives in. Linux does not account network traffic per
+// process, so this is the closest /proc gets.
+func readNetCounters(pid int) (netCounters, error) {
+	var n netCounters
+	f, err := os.Open(fmt.Sprintf("/proc/%d/net/dev", pid))
+	if err != nil {
+		return n, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for line := 0; sc.Scan(); line++ {
+		// Two header lines
+		if line < 2 {
+			continue
+		}
+		name, data, ok := strings.Cut(sc.Text(), ":")
+		if !ok || strings.TrimSpace(name) == "lo" {
+			continue
+		}
+		fields := strings.Fields(data)
+		if len(fields) < 9 {
+			continue
+		}
+		rx, _ := strconv.ParseUint(fields[0], 10, 64)
+		tx, _ := strconv.ParseUint(fields[8], 10, 64)
+		n.rx += rx
+		n.tx += tx
+	}
+	return n, sc.Err()
+}
+
+func sameNetNamespace(pid int) bool {
+	self, err := os.Readlink("/proc/self/ns/net")
+	if err != nil {
+		return false
+	}
+	other, err := os.Readlink(fmt.Sprintf("/proc/%d/ns/net", pid))
+	return err == nil && self == other
+}
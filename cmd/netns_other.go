@@ -0,0 +1,18 @@
+//go:build !linux
+
+// cmd/netns_other.go
+package cmd
+
+import "errors"
+
+type netCounters struct {
+	rx, tx uint64
+}
+
+func readNetCounters(pid int) (netCounters, error) {
+	return netCounters{}, errors.New("network counters are only available on Linux")
+}
+
+func sameNetNamespace(pid int) bool {
+	return false
+}
@@ -0,0 +1,42 @@
+//go:build !unix
+
+// cmd/proc_other.go
+package cmd
+
+import (
+	"os"
+	"os/exec"
+)
+
+// forwardedSignals only catches Ctrl+C: the console delivers it to the
+// command as well, so vigil just has to survive long enough to report.
+func forwardedSignals(foreground bool) []os.Signal {
+	return []os.Signal{os.Interrupt}
+}
+
+func setProcessGroup(c *exec.Cmd, foreground bool) (restore func()) {
+	return func() {}
+}
+
+func signalProcess(p *os.Process, sig os.Signal, group bool) error {
+	if sig == os.Kill {
+		return p.Kill()
+	}
+	return nil
+}
+
+func terminateProcess(p *os.Process, group bool) error {
+	return p.Kill()
+}
+
+func killProcess(p *os.Process, group bool) error {
+	return p.Kill()
+}
+
+func exitSignal(state *os.ProcessState) (name string, num int, coreDumped bool) {
+	return "", 0, false
+}
+
+func peakRSS(state *os.ProcessState) uint64 {
+	return 0
+}
@@ -0,0 +1,91 @@
+//go:build unix
+
+// cmd/proc_unix.go
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// forwardedSignals lists the signals vigil passes on to the command. In
+// foreground mode the terminal already delivers SIGINT and SIGQUIT to the
+// command, so only signals aimed at vigil itself are forwarded.
+func forwardedSignals(foreground bool) []os.Signal {
+	if foreground {
+		return []os.Signal{syscall.SIGTERM, syscall.SIGHUP}
+	}
+	return []os.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT}
+}
+
+// setProcessGroup starts the command in its own process group so signals
+// and limit kills reach every process it spawns. When vigil owns the
+// terminal the group is handed it, or commands reading from it (editors,
+// password prompts) would be stopped by SIGTTIN. The returned function
+// hands the terminal back once the command has exited.
+func setProcessGroup(c *exec.Cmd, foreground bool) (restore func()) {
+	restore = func() {}
+	if foreground {
+		return restore
+	}
+	c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	tty := int(os.Stdin.Fd())
+	pgrp, err := unix.IoctlGetInt(tty, unix.TIOCGPGRP)
+	own, _ := unix.Getpgid(0)
+	if err != nil || pgrp != own {
+		// stdin is not a terminal, or vigil runs in the background
+		return restore
+	}
+	c.SysProcAttr.Foreground = true
+	c.SysProcAttr.Ctty = tty
+	return func() {
+		// A background group taking the terminal gets SIGTTOU
+		signal.Ignore(syscall.SIGTTOU)
+		defer signal.Reset(syscall.SIGTTOU)
+		unix.IoctlSetPointerInt(tty, unix.TIOCSPGRP, pgrp)
+	}
+}
+
+func signalProcess(p *os.Process, sig os.Signal, group bool) error {
+	s, ok := sig.(syscall.Signal)
+	if group && ok {
+		return syscall.Kill(-p.Pid, s)
+	}
+	return p.Signal(sig)
+}
+
+func terminateProcess(p *os.Process, group bool) error {
+	return signalProcess(p, syscall.SIGTERM, group)
+}
+
+func killProcess(p *os.Process, group bool) error {
+	return signalProcess(p, syscall.SIGKILL, group)
+}
+
+// exitSignal reports the signal that terminated the process, if any
+func exitSignal(state *os.ProcessState) (name string, num int, coreDumped bool) {
+	ws, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !ws.Signaled() {
+		return "", 0, false
+	}
+	return unix.SignalName(ws.Signal()), int(ws.Signal()), ws.CoreDump()
+}
+
+// peakRSS is the kernel's record of the largest resident set of the
+// process and the children it waited for. Maxrss is in kilobytes except
+// on Apple platforms.
+func peakRSS(state *os.ProcessState) uint64 {
+	ru, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok || ru.Maxrss <= 0 {
+		return 0
+	}
+	if runtime.GOOS == "darwin" || runtime.GOOS == "ios" {
+		return uint64(ru.Maxrss)
+	}
+	return uint64(ru.Maxrss) * 1024
+}
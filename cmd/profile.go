@@ -0,0 +1,329 @@
+// cmd/profile.go
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"time"
+
+	"github.com/sahil3982/vigil/internal/format"
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// Exit codes used when vigil itself, rather than the command, decides the
+// outcome. They follow the conventions of timeout(1) and POSIX shells.
+const (
+	exitRegression    = 123
+	exitLimitExceeded = 124
+	exitCannotRun     = 126
+	exitNotFound      = 127
+)
+
+// profileCommand runs a command to completion, sampling its resource usage
+// every sampleInterval and enforcing the configured limits. onSample, if
+// set, is called with every sample. The error is only non-nil when the
+// command could not be run at all.
+func profileCommand(args []string, onSample func(format.ExecSample)) (format.ExecStat, error) {
+	start := time.Now()
+
+	c := exec.Command(args[0], args[1:]...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	restoreTerminal := setProcessGroup(c, foreground)
+	group := !foreground
+
+	if err := c.Start(); err != nil {
+		return format.ExecStat{Command: c.String()}, err
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, forwardedSignals(foreground)...)
+	go func() {
+		for sig := range sigs {
+			signalProcess(c.Process, sig, group)
+		}
+	}()
+	defer func() {
+		signal.Stop(sigs)
+		close(sigs)
+	}()
+
+	if limits.enforce {
+		if err := applyKernelLimits(c.Process.Pid, limits); err != nil {
+			killProcess(c.Process, group)
+			c.Wait()
+			restoreTerminal()
+			return format.ExecStat{Command: c.String()}, fmt.Errorf("failed to enforce limits: %w", err)
+		}
+	}
+
+	// Network counters are per namespace, so take a reading before the
+	// command has done much
+	netStart, netErr := readNetCounters(c.Process.Pid)
+	netLast := netStart
+	sharedNet := sameNetNamespace(c.Process.Pid)
+
+	// Monitoring
+	var maxRAM float64
+	var io format.IOStat
+	var cpuSum float64
+	var cpuSamples int
+	var treeCPU float64
+	var breach *format.LimitBreach
+	var killTimer *time.Timer
+
+	ticker := time.NewTicker(sampleInterval)
+	done := make(chan bool)
+	finished := make(chan bool)
+
+	go func() {
+		defer close(finished)
+		var p *process.Process
+		takeSample := func(now time.Time) {
+			// The process handle is kept across ticks so CPU
+			// percentages are computed from deltas
+			if p == nil {
+				p, _ = process.NewProcess(int32(c.Process.Pid))
+			}
+			sample := format.ExecSample{Time: now.UTC(), ElapsedSeconds: now.Sub(start).Seconds()}
+			var cpuSeconds float64
+			if p != nil {
+				tree := processTree(p)
+				sample = sampleProcess(p, start, now)
+				sample.RSSBytes = treeRSS(tree)
+				mb := float64(sample.RSSBytes) / (1024 * 1024)
+				if mb > maxRAM {
+					maxRAM = mb
+				}
+				io = maxIO(io, treeIO(tree))
+				sample.ReadBytes = io.ReadBytes
+				sample.WriteBytes = io.WriteBytes
+				if netErr == nil {
+					if n, err := readNetCounters(c.Process.Pid); err == nil {
+						netLast = n
+					}
+				}
+				if onSample != nil {
+					onSample(sample)
+				}
+				if limits.maxCPUSeconds > 0 {
+					treeCPU = max(treeCPU, treeCPUSeconds(tree))
+					cpuSeconds = treeCPU
+				}
+			}
+
+			// Limits
+			if breach == nil {
+				if breach = limits.check(sample, cpuSeconds); breach != nil {
+					killTimer = terminate(c.Process, group, limits.grace)
+				}
+			}
+		}
+		// Sample once right away so commands that finish before the first
+		// tick still report their memory; the CPU reading only sets the
+		// baseline for the first tick
+		takeSample(time.Now())
+		cpu.Percent(0, false)
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				takeSample(now)
+
+				// CPU
+				perc, _ := cpu.Percent(0, false)
+				if len(perc) > 0 {
+					cpuSum += perc[0]
+					cpuSamples++
+				}
+			}
+		}
+	}()
+
+	c.Wait()
+	restoreTerminal()
+	ticker.Stop()
+	close(done)
+	<-finished
+	if netErr == nil {
+		if sharedNet {
+			// The command shared our namespace, so our own view covers
+			// traffic after its last sample too
+			if n, err := readNetCounters(os.Getpid()); err == nil {
+				netLast = n
+			}
+		}
+		io.NetRxBytes = counterDelta(netStart.rx, netLast.rx)
+		io.NetTxBytes = counterDelta(netStart.tx, netLast.tx)
+		io.NetScope = "command"
+		if sharedNet {
+			io.NetScope = "namespace"
+		}
+	}
+	if killTimer != nil {
+		killTimer.Stop()
+	}
+	if maxRAM == 0 {
+		// The command exited before it could be sampled. The kernel's peak
+		// is only an upper bound: it can include vigil's own memory at the
+		// time it started the command.
+		maxRAM = float64(peakRSS(c.ProcessState)) / (1024 * 1024)
+	}
+	if breach == nil {
+		breach = kernelBreach(c.ProcessState, limits)
+	}
+
+	elapsed := time.Since(start).Seconds()
+	avgCPU := 0.0
+	if cpuSamples > 0 {
+		avgCPU = cpuSum / float64(cpuSamples)
+	}
+
+	stat := format.ExecStat{
+		Command:        c.String(),
+		ExitCode:       c.ProcessState.ExitCode(),
+		ElapsedSeconds: elapsed,
+		CPUSeconds:     (c.ProcessState.UserTime() + c.ProcessState.SystemTime()).Seconds(),
+		CPUAvgPercent:  avgCPU,
+		RAMPeakMB:      maxRAM,
+		IO:             &io,
+		LimitBreach:    breach,
+	}
+	if name, num, core := exitSignal(c.ProcessState); name != "" {
+		// Shell convention for commands killed by a signal
+		stat.ExitCode = 128 + num
+		stat.Signal = name
+		stat.CoreDumped = core
+	}
+	return stat, nil
+}
+
+// startExitCode maps a profileCommand error to vigil's exit code
+func startExitCode(err error) int {
+	if errors.Is(err, exec.ErrNotFound) {
+		return exitNotFound
+	}
+	return exitCannotRun
+}
+
+// execExitCode is the code vigil exits with after running a command. It is
+// the same in every output mode: the command's own exit code (128+N when
+// killed by signal N), or exitLimitExceeded when vigil stopped it.
+func execExitCode(stat format.ExecStat) int {
+	if stat.LimitBreach != nil {
+		return exitLimitExceeded
+	}
+	return stat.ExitCode
+}
+
+// processTree returns root followed by all of its live descendants
+func processTree(root *process.Process) []*process.Process {
+	tree := []*process.Process{root}
+	procs, err := process.Processes()
+	if err != nil {
+		return tree
+	}
+	children := make(map[int32][]*process.Process)
+	for _, p := range procs {
+		if ppid, err := p.Ppid(); err == nil && ppid != p.Pid {
+			children[ppid] = append(children[ppid], p)
+		}
+	}
+	seen := map[int32]bool{root.Pid: true}
+	for i := 0; i < len(tree); i++ {
+		for _, child := range children[tree[i].Pid] {
+			if !seen[child.Pid] {
+				seen[child.Pid] = true
+				tree = append(tree, child)
+			}
+		}
+	}
+	return tree
+}
+
+// treeIO sums the IO counters of a process tree. On Linux a parent's
+// counters already include its reaped children, so work done by
+// short-lived processes is not lost.
+func treeIO(tree []*process.Process) format.IOStat {
+	var total format.IOStat
+	for _, p := range tree {
+		c, err := p.IOCounters()
+		if err != nil || c == nil {
+			continue
+		}
+		total.ReadBytes += c.ReadBytes
+		total.WriteBytes += c.WriteBytes
+		total.ReadSyscalls += c.ReadCount
+		total.WriteSyscalls += c.WriteCount
+	}
+	return total
+}
+
+// treeRSS sums the resident memory of a process tree. Pages shared between
+// the processes are counted once per process, as top and ps do.
+func treeRSS(tree []*process.Process) uint64 {
+	var total uint64
+	for _, p := range tree {
+		if m, err := p.MemoryInfo(); err == nil && m != nil {
+			total += m.RSS
+		}
+	}
+	return total
+}
+
+// treeCPUSeconds sums the user and system CPU time of a process tree. A
+// descendant's time drops out of the sum once it exits, so callers keep
+// the highest total seen.
+func treeCPUSeconds(tree []*process.Process) float64 {
+	var total float64
+	for _, p := range tree {
+		if t, err := p.Times(); err == nil && t != nil {
+			total += t.User + t.System
+		}
+	}
+	return total
+}
+
+// maxIO keeps the highest value seen for each counter, since a descendant
+// exiting between samples can briefly lower the tree total
+func maxIO(a, b format.IOStat) format.IOStat {
+	return format.IOStat{
+		ReadBytes:     max(a.ReadBytes, b.ReadBytes),
+		WriteBytes:    max(a.WriteBytes, b.WriteBytes),
+		ReadSyscalls:  max(a.ReadSyscalls, b.ReadSyscalls),
+		WriteSyscalls: max(a.WriteSyscalls, b.WriteSyscalls),
+	}
+}
+
+func counterDelta(start, end uint64) uint64 {
+	if end < start {
+		return 0
+	}
+	return end - start
+}
+
+// sampleProcess takes a point-in-time reading of a running process. Fields
+// that cannot be read are left zero; RSS and IO are filled in from the
+// whole tree by the caller.
+func sampleProcess(p *process.Process, start, now time.Time) format.ExecSample {
+	s := format.ExecSample{
+		Time:           now.UTC(),
+		ElapsedSeconds: now.Sub(start).Seconds(),
+	}
+	if m, err := p.MemoryInfo(); err == nil && m != nil {
+		s.RSSBytes = m.RSS
+	}
+	if c, err := p.Percent(0); err == nil {
+		s.CPUPercent = c
+	}
+	if t, err := p.NumThreads(); err == nil {
+		s.Threads = t
+	}
+	return s
+}
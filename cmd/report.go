@@ -0,0 +1,41 @@
+// cmd/report.go
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sahil3982/vigil/internal/record"
+	"github.com/spf13/cobra"
+)
+
+var reportHTML bool
+
+var reportCmd = &cobra.Command{
+	Use:   "report <recording>",
+	Short: "Summarize a recording made with `vigil exec --record`",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		samples, err := record.Read(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "✗ Failed to read recording: %v\n", err)
+			os.Exit(1)
+		}
+
+		title := filepath.Base(args[0])
+		if reportHTML {
+			err = record.WriteHTML(os.Stdout, title, samples)
+		} else {
+			err = record.WriteText(os.Stdout, title, samples)
+		}
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	reportCmd.Flags().BoolVar(&reportHTML, "html", false, "Render a self-contained HTML chart instead of sparklines")
+	rootCmd.AddCommand(reportCmd)
+}
@@ -2,14 +2,19 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"strings"
 
+	"github.com/sahil3982/vigil/internal/format"
 	"github.com/spf13/cobra"
 )
 
 var (
-	jsonFlag bool
-	quiet    bool
+	jsonFlag     bool
+	quiet        bool
+	formatName   string
+	templateFlag string
 )
 
 var rootCmd = &cobra.Command{
@@ -17,6 +22,9 @@ var rootCmd = &cobra.Command{
 	Short: "👁️  Lightweight system monitor for devs, CI, and edge devices",
 	Long: `vigil — check CPU, memory, disk, and profile commands in style.
 Fast. Static. No dependencies. Built for terminals.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		loadConfig(cmd)
+	},
 }
 
 // Execute executes the root command.
@@ -32,4 +40,36 @@ func Execute() {
 func init() {
 	rootCmd.PersistentFlags().BoolVar(&jsonFlag, "json", false, "Output as JSON")
 	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Quiet mode: minimal output (e.g., just number)")
-}
\ No newline at end of file
+	rootCmd.PersistentFlags().StringVarP(&formatName, "format", "o", "", "Output format: "+strings.Join(format.Names(), ", ")+" (overrides --json)")
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Config file (default ~/.config/vigil/config.yaml or /etc/vigil/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&scopeFlag, "scope", "host", "Report the whole host or the cgroup vigil runs in (host, cgroup)")
+	rootCmd.PersistentFlags().StringVar(&templateFlag, "template", "", "Render output with a Go template, e.g. '{{.UsedPercent}}' (implies --format template)")
+}
+
+// newFormatter returns the formatter selected by --format (or --template),
+// then --json, then the output section of the config file
+func newFormatter() format.Formatter {
+	name, tmpl := formatName, templateFlag
+	switch {
+	case name == "" && tmpl != "":
+		name = "template"
+	case name == "" && jsonFlag:
+		name = "json"
+	case name == "":
+		name = cfg.Output.Format
+		if tmpl == "" {
+			tmpl = cfg.Output.Template
+		}
+	}
+	f, err := format.Named(name, format.Options{
+		Quiet:    quiet,
+		Template: tmpl,
+		Warning:  cfg.Thresholds.Warning,
+		Critical: cfg.Thresholds.Critical,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+		os.Exit(1)
+	}
+	return f
+}
@@ -0,0 +1,571 @@
+// cmd/sampler.go
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/sahil3982/vigil/internal/cgroup"
+	"github.com/sahil3982/vigil/internal/config"
+	"github.com/sahil3982/vigil/internal/diskio"
+	"github.com/sahil3982/vigil/internal/engine"
+	"github.com/sahil3982/vigil/internal/forecast"
+	"github.com/sahil3982/vigil/internal/format"
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/host"
+	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// staleAfter is how many missed intervals make a source stale even when
+// its last collection did not fail
+const staleAfter = 3
+
+// detailSources are served by their own endpoints instead of being copied
+// into every snapshot (and so into every history point)
+var detailSources = map[string]bool{
+	"process_list": true, "system_info": true, "interfaces": true,
+	"containers": true, "units": true, "io": true,
+}
+
+// source is one independently collected section of the metrics snapshot
+type source struct {
+	name     string
+	interval time.Duration
+	collect  func() (map[string]interface{}, error)
+}
+
+type sourceState struct {
+	value   map[string]interface{}
+	updated time.Time
+	err     error
+}
+
+// sampler collects every source in the background on its own interval and
+// publishes immutable snapshots. Handlers only ever read the latest one,
+// so the number of clients does not change the load on the host.
+type sampler struct {
+	sources []source
+	// group is the cgroup being reported under --scope cgroup, or nil
+	group *cgroup.Group
+
+	mu     sync.RWMutex
+	state  map[string]*sourceState
+	latest map[string]interface{}
+}
+
+func newSampler(intervals config.Sampler, g *cgroup.Group) *sampler {
+	s := &sampler{state: map[string]*sourceState{}, group: g}
+	s.sources = []source{
+		{"cpu", time.Duration(intervals.CPU), cpuSource(g)},
+		{"memory", time.Duration(intervals.Memory), memorySource(g)},
+		{"disk", time.Duration(intervals.Disk), diskSource},
+		{"network", time.Duration(intervals.Network), networkSource},
+		{"host", time.Duration(intervals.Host), hostSource},
+		{"processes", time.Duration(intervals.Processes), processesSource(g)},
+		{"process_list", time.Duration(intervals.Processes), processListSource(g)},
+		{"system_info", time.Duration(intervals.Host), systemInfoSource},
+		{"interfaces", time.Duration(intervals.Network), interfacesSource},
+		{"sensors", time.Duration(intervals.Sensors), sensorsSource},
+		{"io", time.Duration(intervals.IO), ioSource()},
+	}
+	for _, src := range s.sources {
+		s.state[src.name] = &sourceState{}
+	}
+	return s
+}
+
+// add registers an optional source; it must be called before start
+func (s *sampler) add(src source) {
+	s.sources = append(s.sources, src)
+	s.state[src.name] = &sourceState{}
+}
+
+// start collects every source once, so the first request already has
+// data, then keeps collecting until stop is closed
+func (s *sampler) start(stop <-chan struct{}) {
+	for _, src := range s.sources {
+		s.collect(src)
+	}
+	s.publish()
+	for _, src := range s.sources {
+		go s.loop(src, stop)
+	}
+}
+
+func (s *sampler) loop(src source, stop <-chan struct{}) {
+	ticker := time.NewTicker(src.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			s.collect(src)
+			s.publish()
+		}
+	}
+}
+
+// collect runs one source. A failure keeps the previous value, which
+// publish then marks stale.
+func (s *sampler) collect(src source) {
+	value, err := src.collect()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := s.state[src.name]
+	st.err = err
+	if err == nil {
+		st.value = value
+		st.updated = time.Now()
+	}
+}
+
+// publish assembles a new snapshot from the latest value of every source
+func (s *sampler) publish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	snapshot := map[string]interface{}{"timestamp": now.UTC(), "scope": "host"}
+	if s.group != nil {
+		snapshot["scope"] = scopeCgroup
+	}
+	status := map[string]interface{}{}
+	errs := map[string]string{}
+	anyStale := false
+	for _, src := range s.sources {
+		st := s.state[src.name]
+		stale := st.err != nil || st.updated.IsZero() || now.Sub(st.updated) > staleAfter*src.interval
+		anyStale = anyStale || stale
+		entry := map[string]interface{}{"stale": stale}
+		if !st.updated.IsZero() {
+			entry["updated_at"] = st.updated.UTC()
+		}
+		if st.err != nil {
+			entry["error"] = st.err.Error()
+			errs[src.name] = st.err.Error()
+		}
+		status[src.name] = entry
+		if !detailSources[src.name] {
+			snapshot[src.name] = st.value
+		}
+	}
+	if h := s.state["host"].value; h != nil {
+		// Host details rarely change, but uptime should stay current
+		withUptime := make(map[string]interface{}, len(h)+1)
+		for k, v := range h {
+			withUptime[k] = v
+		}
+		if boot, ok := h["boot_time"].(uint64); ok {
+			withUptime["uptime_seconds"] = uint64(now.Unix()) - boot
+		}
+		delete(withUptime, "boot_time")
+		snapshot["host"] = withUptime
+	}
+
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	system := map[string]interface{}{
+		"goroutines":   runtime.NumGoroutine(),
+		"cgo_calls":    runtime.NumCgoCall(),
+		"go_mem_alloc": m.Alloc,
+		"go_mem_sys":   m.Sys,
+		"go_mem_heap":  m.HeapAlloc,
+		"go_mem_stack": m.StackInuse,
+		"go_gc_count":  m.NumGC,
+		"go_gc_pause":  m.PauseTotalNs,
+	}
+	if p := s.state["processes"].value; p != nil {
+		system["process_count"] = p["count"]
+	}
+	snapshot["system"] = system
+
+	alertValues := map[string]float64{
+		"cpu":    percentOf(s.state["cpu"].value, "percent"),
+		"memory": percentOf(s.state["memory"].value, "percent"),
+		"swap":   percentOf(s.state["memory"].value, "swap_percent"),
+		"disk":   percentOf(s.state["disk"].value, "percent"),
+	}
+	// A disk that is not filling up has no time until full to alert on
+	if days, ok := s.state["disk"].value["days_until_full"].(*float64); ok && days != nil {
+		alertValues["days_until_full"] = *days
+	}
+	// Sensor alerts only apply to the sensors this host has
+	sensors := s.state["sensors"].value
+	if t, ok := sensors["max_celsius"].(float64); ok {
+		alertValues["temperature"] = t
+	}
+	if flags, ok := sensors["throttled"].([]string); ok {
+		alertValues["throttled"] = float64(len(flags))
+	}
+	if batteries, ok := sensors["batteries"].([]format.BatteryStat); ok && len(batteries) > 0 {
+		lowest := batteries[0].Percent
+		for _, b := range batteries[1:] {
+			lowest = min(lowest, b.Percent)
+		}
+		alertValues["battery"] = lowest
+	}
+	snapshot["alerts"] = checkAlerts(alertValues)
+	snapshot["sources"] = status
+	snapshot["errors"] = errs
+	snapshot["stale"] = anyStale
+	s.latest = snapshot
+}
+
+func percentOf(section map[string]interface{}, key string) float64 {
+	v, _ := section[key].(float64)
+	return v
+}
+
+// detail returns the latest value of a source and its entry under
+// "sources" in the snapshot. ok is false for an unknown source.
+func (s *sampler) detail(name string) (value, status map[string]interface{}, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	st, ok := s.state[name]
+	if !ok {
+		return nil, nil, false
+	}
+	status, _ = s.latest["sources"].(map[string]interface{})[name].(map[string]interface{})
+	return st.value, status, true
+}
+
+// snapshot returns the latest published metrics. Callers must not modify
+// the map; it is shared with every other reader.
+func (s *sampler) snapshot() map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.latest
+}
+
+// cpuSource reports usage since its previous collection and reads the
+// CPU model once; only usage and load change
+func cpuSource(g *cgroup.Group) func() (map[string]interface{}, error) {
+	var frequency string
+	usage := newCPUSampler(g)
+	return func() (map[string]interface{}, error) {
+		u, err := usage.Sample()
+		if err != nil {
+			return nil, err
+		}
+		if frequency == "" {
+			if info, err := cpu.Info(); err == nil && len(info) > 0 {
+				frequency = fmt.Sprintf("%.2f GHz", info[0].Mhz/1000)
+			}
+		}
+		cores, _ := cpu.Counts(true)
+		section := map[string]interface{}{
+			"percent":        u.Percent,
+			"per_core":       u.PerCore,
+			"cores_physical": cores,
+			"cores_logical":  runtime.NumCPU(),
+			"frequency":      frequency,
+			"load_average":   getLoadAverage(),
+		}
+		if c := u.Cgroup; c != nil {
+			section["quota_cores"] = c.QuotaCores
+			section["throttled_periods"] = c.ThrottledPeriods
+			section["throttled_seconds"] = c.ThrottledSeconds
+			section["periods"] = c.Periods
+		}
+		return section, nil
+	}
+}
+
+// memorySource reports host memory, or the cgroup's working set against
+// its limit; swap, cache and buffers are always host-wide
+func memorySource(g *cgroup.Group) func() (map[string]interface{}, error) {
+	return func() (map[string]interface{}, error) {
+		memInfo, err := virtualMemory()
+		if err != nil {
+			return nil, err
+		}
+		swapInfo, err := mem.SwapMemory()
+		if err != nil {
+			return nil, err
+		}
+		stat, err := memStat(g)
+		if err != nil {
+			return nil, err
+		}
+		section := map[string]interface{}{
+			"total":        stat.TotalBytes,
+			"available":    stat.AvailableBytes,
+			"used":         stat.UsedBytes,
+			"free":         stat.FreeBytes,
+			"percent":      stat.UsedPercent,
+			"swap_total":   swapInfo.Total,
+			"swap_used":    swapInfo.Used,
+			"swap_percent": swapInfo.UsedPercent,
+			"cached":       memInfo.Cached,
+			"buffers":      memInfo.Buffers,
+		}
+		if stat.LimitBytes > 0 {
+			section["limit"] = stat.LimitBytes
+		}
+		return section, nil
+	}
+}
+
+// trendPoints is how many disk readings the forecast keeps per window. The
+// history is too short for it (by default 1000 points 5s apart, 83
+// minutes, against a 24h window), so readings are kept apart from it, at
+// most one every window/trendPoints.
+const trendPoints = 288
+
+var (
+	diskTrend      []forecast.Point
+	diskTrendMutex sync.Mutex
+)
+
+// recordDiskTrend adds a reading to diskTrend when the last one kept is old
+// enough, drops the ones that left the window, and returns the kept
+// readings followed by this one
+func recordDiskTrend(p forecast.Point, window time.Duration) []forecast.Point {
+	diskTrendMutex.Lock()
+	defer diskTrendMutex.Unlock()
+	n := len(diskTrend)
+	if n == 0 || p.Time.Sub(diskTrend[n-1].Time) >= window/trendPoints {
+		diskTrend = forecast.Recent(append(diskTrend, p), window)
+		return append([]forecast.Point(nil), diskTrend...)
+	}
+	return append(append([]forecast.Point(nil), diskTrend...), p)
+}
+
+// seedDiskTrend fills diskTrend from history loaded at startup
+func seedDiskTrend(points []forecast.Point, window time.Duration) {
+	for _, p := range forecast.Recent(points, window) {
+		recordDiskTrend(p, window)
+	}
+}
+
+// diskSource reports the root filesystem with a forecast of when it fills
+// up, fitted to the readings kept so far and the current one
+func diskSource() (map[string]interface{}, error) {
+	diskInfo, err := disk.Usage("/")
+	if err != nil {
+		return nil, err
+	}
+	diskIO, _ := disk.IOCounters()
+	window := time.Duration(cfg.Forecast.Window)
+	points := recordDiskTrend(forecast.Point{Time: time.Now(), Used: float64(diskInfo.Used)}, window)
+	f := diskForecast(points, window, diskInfo.Used, diskInfo.Used+diskInfo.Free)
+	return map[string]interface{}{
+		"total":          diskInfo.Total,
+		"free":           diskInfo.Free,
+		"used":           diskInfo.Used,
+		"percent":        diskInfo.UsedPercent,
+		"inodes_total":   diskInfo.InodesTotal,
+		"inodes_used":    diskInfo.InodesUsed,
+		"inodes_free":    diskInfo.InodesFree,
+		"inodes_percent": diskInfo.InodesUsedPercent,
+		"io_read_bytes":  getDiskIOBytes(diskIO, "read"),
+		"io_write_bytes": getDiskIOBytes(diskIO, "write"),
+		// days_until_full is null until there is enough history, and
+		// while usage is not growing
+		"growth_bytes_per_day": f.GrowthBytesPerDay,
+		"days_until_full":      f.DaysUntilFull,
+	}, nil
+}
+
+func networkSource() (map[string]interface{}, error) {
+	netIO, err := net.IOCounters(false)
+	if err != nil {
+		return nil, err
+	}
+	if len(netIO) == 0 {
+		return nil, fmt.Errorf("no network counters reported")
+	}
+	return map[string]interface{}{
+		"bytes_sent":   netIO[0].BytesSent,
+		"bytes_recv":   netIO[0].BytesRecv,
+		"packets_sent": netIO[0].PacketsSent,
+		"packets_recv": netIO[0].PacketsRecv,
+		"err_in":       netIO[0].Errin,
+		"err_out":      netIO[0].Errout,
+		"drop_in":      netIO[0].Dropin,
+		"drop_out":     netIO[0].Dropout,
+	}, nil
+}
+
+func hostSource() (map[string]interface{}, error) {
+	hostInfo, err := host.Info()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"hostname":         hostInfo.Hostname,
+		"os":               hostInfo.OS,
+		"platform":         hostInfo.Platform,
+		"platform_family":  hostInfo.PlatformFamily,
+		"platform_version": hostInfo.PlatformVersion,
+		"kernel_version":   hostInfo.KernelVersion,
+		"boot_time":        hostInfo.BootTime,
+	}, nil
+}
+
+// processesSource only counts processes for the snapshot;
+// processListSource reads them in full. Enumerating them is the most
+// expensive collection, so both run least often by default.
+func processesSource(g *cgroup.Group) func() (map[string]interface{}, error) {
+	return func() (map[string]interface{}, error) {
+		if g != nil {
+			pids, err := g.Procs()
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"count": len(pids)}, nil
+		}
+		pids, err := process.Pids()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"count": len(pids)}, nil
+	}
+}
+
+// processListSource reads every process with its CPU and memory for
+// /api/v1/processes
+func processListSource(g *cgroup.Group) func() (map[string]interface{}, error) {
+	return func() (map[string]interface{}, error) {
+		processes, err := scopedProcesses(g)
+		if err != nil {
+			return nil, err
+		}
+		list := make([]map[string]interface{}, 0, len(processes))
+		for _, p := range processes {
+			name, _ := p.Name()
+			mem, _ := p.MemoryInfo()
+			cpu, _ := p.CPUPercent()
+
+			// Handle nil memory info
+			var rss, vms uint64
+			if mem != nil {
+				rss = mem.RSS
+				vms = mem.VMS
+			}
+
+			list = append(list, map[string]interface{}{
+				"pid":     p.Pid,
+				"name":    name,
+				"cpu":     cpu,
+				"mem_rss": rss,
+				"mem_vms": vms,
+			})
+		}
+		return map[string]interface{}{"processes": list}, nil
+	}
+}
+
+// systemInfoSource reads everything gopsutil knows about the host for
+// /api/v1/system/info
+func systemInfoSource() (map[string]interface{}, error) {
+	info, err := host.Info()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"info": *info}, nil
+}
+
+// interfacesSource reads the counters of every network interface for
+// /api/v1/network
+func interfacesSource() (map[string]interface{}, error) {
+	stats, err := net.IOCounters(true)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"interfaces": stats}, nil
+}
+
+// unitsSource reads every systemd unit with processes, plus failed ones.
+// Unit states that could not be read are reported under "errors".
+func unitsSource() func() (map[string]interface{}, error) {
+	units := &unitSampler{}
+	return func() (map[string]interface{}, error) {
+		list, failed, err := units.Sample()
+		if err != nil {
+			return nil, err
+		}
+		sortUnits(list.Units, "cpu")
+		errs := map[string]string{}
+		for _, e := range failed {
+			errs[e.Source] = e.Err.Error()
+		}
+		return map[string]interface{}{
+			"units":  list.Units,
+			"errors": errs,
+		}, nil
+	}
+}
+
+// ioSource reports every block device's activity since its previous
+// collection; /api/v1/io filters the devices
+func ioSource() func() (map[string]interface{}, error) {
+	sampler := diskio.New()
+	return func() (map[string]interface{}, error) {
+		devices, elapsed, err := sampler.Sample()
+		if err != nil {
+			return nil, err
+		}
+		stat := diskIOStat(devices, elapsed)
+		return map[string]interface{}{
+			"interval_seconds": stat.IntervalSeconds,
+			"devices":          stat.Devices,
+		}, nil
+	}
+}
+
+// sensorsSource reads temperatures, fans, throttling and batteries. A
+// host without sensors gets an empty section rather than an error.
+func sensorsSource() (map[string]interface{}, error) {
+	stat, err := readSensors()
+	if err != nil {
+		return nil, err
+	}
+	if stat == nil {
+		return map[string]interface{}{}, nil
+	}
+	section := map[string]interface{}{
+		"temperatures":         stat.Temperatures,
+		"fans":                 stat.Fans,
+		"throttled":            stat.Throttled,
+		"throttled_since_boot": stat.ThrottledSinceBoot,
+		"batteries":            stat.Batteries,
+	}
+	if len(stat.Temperatures) > 0 {
+		section["max_celsius"] = stat.MaxCelsius
+	}
+	if stat.ACOnline != nil {
+		section["ac_online"] = *stat.ACOnline
+	}
+	return section, nil
+}
+
+// containersSource reads every running container through the engine API.
+// Containers whose stats fail are listed under "errors".
+func containersSource(client *engine.Client) func() (map[string]interface{}, error) {
+	return func() (map[string]interface{}, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		list, failed, err := collectContainers(ctx, client, false)
+		if err != nil {
+			return nil, err
+		}
+		sortContainers(list.Containers, "cpu")
+		errs := map[string]string{}
+		for _, e := range failed {
+			errs[e.Source] = e.Err.Error()
+		}
+		return map[string]interface{}{
+			"socket":     list.Socket,
+			"containers": list.Containers,
+			"errors":     errs,
+		}, nil
+	}
+}
@@ -0,0 +1,119 @@
+// cmd/scope.go
+package cmd
+
+import (
+	"errors"
+	"runtime"
+
+	"github.com/sahil3982/vigil/internal/cgroup"
+	"github.com/sahil3982/vigil/internal/cpuusage"
+	"github.com/sahil3982/vigil/internal/format"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+const scopeCgroup = "cgroup"
+
+var scopeFlag string
+
+// scopedCgroup returns vigil's own cgroup under --scope cgroup, and nil for
+// the host scope. It exits when the cgroup cannot be found.
+func scopedCgroup() *cgroup.Group {
+	if scopeFlag != scopeCgroup {
+		return nil
+	}
+	g, err := cgroup.Self()
+	if err != nil {
+		failSource("cgroup", err)
+	}
+	return g
+}
+
+// newCPUSampler measures the host, or the cgroup when g is set
+func newCPUSampler(g *cgroup.Group) *cpuusage.Sampler {
+	if g == nil {
+		return cpuusage.New()
+	}
+	return cpuusage.NewCgroup(g)
+}
+
+func cpuStat(u cpuusage.Usage) format.CPUStat {
+	stat := format.CPUStat{Percent: u.Percent, Cores: len(u.PerCore)}
+	if c := u.Cgroup; c != nil {
+		stat.Scope = scopeCgroup
+		stat.Cores = runtime.NumCPU()
+		stat.QuotaCores = c.QuotaCores
+		stat.Throttling = &format.ThrottleStat{
+			Periods:          c.Periods,
+			ThrottledPeriods: c.ThrottledPeriods,
+			ThrottledSeconds: c.ThrottledSeconds,
+		}
+	}
+	return stat
+}
+
+// memStat reads host memory, or the cgroup's working set against its
+// limit (host memory when it has none)
+func memStat(g *cgroup.Group) (format.MemStat, error) {
+	v, err := virtualMemory()
+	if err != nil {
+		return format.MemStat{}, err
+	}
+	if g == nil {
+		return format.MemStat{
+			TotalBytes:     v.Total,
+			UsedBytes:      v.Used,
+			FreeBytes:      v.Free,
+			AvailableBytes: v.Available,
+			UsedPercent:    v.UsedPercent,
+		}, nil
+	}
+
+	m, err := g.Memory()
+	if errors.Is(err, cgroup.ErrRoot) {
+		// vigil runs in the root cgroup, which is the whole host
+		return format.MemStat{
+			Scope:          scopeCgroup,
+			TotalBytes:     v.Total,
+			UsedBytes:      v.Used,
+			FreeBytes:      v.Free,
+			AvailableBytes: v.Available,
+			UsedPercent:    v.UsedPercent,
+		}, nil
+	}
+	if err != nil {
+		return format.MemStat{}, err
+	}
+	stat := format.MemStat{
+		Scope:      scopeCgroup,
+		TotalBytes: v.Total,
+		UsedBytes:  m.WorkingSetBytes,
+		LimitBytes: m.LimitBytes,
+	}
+	if m.LimitBytes > 0 && m.LimitBytes < v.Total {
+		stat.TotalBytes = m.LimitBytes
+	}
+	if stat.UsedBytes < stat.TotalBytes {
+		stat.FreeBytes = stat.TotalBytes - stat.UsedBytes
+	}
+	stat.AvailableBytes = stat.FreeBytes
+	stat.UsedPercent = float64(stat.UsedBytes) / float64(stat.TotalBytes) * 100
+	return stat, nil
+}
+
+// scopedProcesses lists every process on the host, or those in the cgroup
+func scopedProcesses(g *cgroup.Group) ([]*process.Process, error) {
+	if g == nil {
+		return process.Processes()
+	}
+	pids, err := g.Procs()
+	if err != nil {
+		return nil, err
+	}
+	procs := make([]*process.Process, 0, len(pids))
+	for _, pid := range pids {
+		if p, err := process.NewProcess(pid); err == nil {
+			procs = append(procs, p)
+		}
+	}
+	return procs, nil
+}
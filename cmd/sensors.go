@@ -0,0 +1,74 @@
+// cmd/sensors.go
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/sahil3982/vigil/internal/format"
+	"github.com/sahil3982/vigil/internal/sensors"
+	"github.com/spf13/cobra"
+)
+
+var sensorsCmd = &cobra.Command{
+	Use:   "sensors",
+	Short: "Show temperatures, fans, throttling and battery charge",
+	Run: func(cmd *cobra.Command, args []string) {
+		stat, err := readSensors()
+		if err != nil {
+			failSource("sensors", err)
+		}
+		if stat == nil {
+			failSource("sensors", fmt.Errorf("no temperature, fan or battery sensors found: %w", fs.ErrNotExist))
+		}
+		f := newFormatter()
+		if err := format.Write(f, os.Stdout, *stat); err != nil {
+			failOutput(err)
+		}
+	},
+}
+
+// readSensors reads every hardware sensor, or returns nil when the host
+// has none, as is usual for VMs
+func readSensors() (*format.SensorsStat, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	r, err := sensors.Read(ctx, sensors.Root())
+	if err != nil {
+		return nil, err
+	}
+	if r.Empty() {
+		return nil, nil
+	}
+
+	stat := &format.SensorsStat{
+		Time:               time.Now().UTC(),
+		Temperatures:       make([]format.TempStat, len(r.Temperatures)),
+		Fans:               make([]format.FanStat, len(r.Fans)),
+		Throttled:          r.Throttled,
+		ThrottledSinceBoot: r.ThrottledSinceBoot,
+		Batteries:          make([]format.BatteryStat, len(r.Batteries)),
+		ACOnline:           r.ACOnline,
+	}
+	for i, t := range r.Temperatures {
+		stat.Temperatures[i] = format.TempStat{Sensor: t.Sensor, Celsius: t.Celsius, HighCelsius: t.High, CriticalCelsius: t.Critical}
+		// Seeded from the first reading: below freezing, all can be negative
+		if i == 0 || t.Celsius > stat.MaxCelsius {
+			stat.MaxCelsius = t.Celsius
+		}
+	}
+	for i, f := range r.Fans {
+		stat.Fans[i] = format.FanStat{Sensor: f.Sensor, RPM: f.RPM}
+	}
+	for i, b := range r.Batteries {
+		stat.Batteries[i] = format.BatteryStat{Name: b.Name, Percent: b.Percent, Status: b.Status}
+	}
+	return stat, nil
+}
+
+func init() {
+	rootCmd.AddCommand(sensorsCmd)
+}
@@ -2,28 +2,49 @@
 package cmd
 
 import (
+	"bufio"
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	stdnet "net"
 	"net/http"
 	"os"
-	"runtime"
+	"os/signal"
+	"strconv"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
+	"unicode"
 
 	"github.com/fatih/color"
-	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/sahil3982/vigil/dashboard"
+	"github.com/sahil3982/vigil/internal/config"
+	"github.com/sahil3982/vigil/internal/engine"
+	"github.com/sahil3982/vigil/internal/format"
+	"github.com/sahil3982/vigil/internal/systemd"
 	"github.com/shirou/gopsutil/v3/disk"
 	"github.com/shirou/gopsutil/v3/host"
 	"github.com/shirou/gopsutil/v3/load"
-	"github.com/shirou/gopsutil/v3/mem"
 	"github.com/shirou/gopsutil/v3/net"
-	"github.com/shirou/gopsutil/v3/process"
 	"github.com/spf13/cobra"
 )
 
 var (
 	port           int
+	listenAddr     string
+	tlsCert        string
+	tlsKey         string
+	readTimeout    time.Duration
+	writeTimeout   time.Duration
 	historyLimit   int
+	historyFile    string
+	dashboardDir   string
+	streamInterval time.Duration
+	metrics        *sampler
 	metricsHistory []map[string]interface{}
 	historyMutex   sync.RWMutex
 )
@@ -38,143 +59,188 @@ var serveCmd = &cobra.Command{
   • Network statistics
   • Alerting capabilities`,
 	Run: func(cmd *cobra.Command, args []string) {
-		// Initialize history collector
-		go collectHistoryWorker()
+		addr := flagOr(cmd, "listen", listenAddr, cfg.Serve.Listen)
+		if cmd.Flags().Changed("port") && !cmd.Flags().Changed("listen") {
+			addr = fmt.Sprintf("127.0.0.1:%d", port)
+		}
+		historyLimit = flagOr(cmd, "history-limit", historyLimit, cfg.History.Limit)
+		historyFile = flagOr(cmd, "history-file", historyFile, cfg.History.File)
+		certFile := flagOr(cmd, "tls-cert", tlsCert, cfg.Serve.TLSCert)
+		keyFile := flagOr(cmd, "tls-key", tlsKey, cfg.Serve.TLSKey)
+		if (certFile == "") != (keyFile == "") {
+			color.Red(" --tls-cert and --tls-key must be given together")
+			os.Exit(1)
+		}
+
+		if historyFile != "" {
+			if err := loadHistory(historyFile); err != nil {
+				color.Red(" Failed to load history: %v", err)
+				os.Exit(1)
+			}
+		}
+		// An ndjson exporter's file usually reaches further back, so the
+		// forecast does not start over on every restart
+		if path := storedHistory(); path != "" && path != historyFile {
+			points, _ := readDiskPoints(path)
+			seedDiskTrend(points, time.Duration(cfg.Forecast.Window))
+		}
 
 		// API Endpoints
-		http.HandleFunc("/api/v1/metrics", handleMetrics)
-		http.HandleFunc("/api/v1/metrics/history", handleMetricsHistory)
-		http.HandleFunc("/api/v1/system/info", handleSystemInfo)
-		http.HandleFunc("/api/v1/processes", handleProcesses)
-		http.HandleFunc("/api/v1/health", handleHealthCheck)
-		http.HandleFunc("/api/v1/network", handleNetworkStats)
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/v1/metrics", handleMetrics)
+		mux.HandleFunc("/api/v1/metrics/history", handleMetricsHistory)
+		mux.HandleFunc("/api/v1/system/info", handleSystemInfo)
+		mux.HandleFunc("/api/v1/processes", handleProcesses)
+		mux.HandleFunc("/api/v1/containers", handleContainers)
+		mux.HandleFunc("/api/v1/units", handleUnits)
+		mux.HandleFunc("/api/v1/io", handleIO)
+		mux.HandleFunc("/api/v1/health", handleHealthCheck)
+		mux.HandleFunc("/api/v1/network", handleNetworkStats)
+		every := flagOr(cmd, "stream-interval", streamInterval, time.Duration(cfg.Serve.StreamInterval))
+		if every <= 0 {
+			color.Red(" --stream-interval must be positive")
+			os.Exit(1)
+		}
+		metrics = newSampler(cfg.Sampler, scopedCgroup())
+		// Containers are reported when a Docker or Podman socket is around
+		socket := cfg.Containers.Socket
+		if socket == "" {
+			socket = engine.DefaultSocket()
+		}
+		if socket != "" {
+			metrics.add(source{"containers", time.Duration(cfg.Sampler.Containers), containersSource(engine.New(socket))})
+		}
+		if systemd.Running() {
+			metrics.add(source{"units", time.Duration(cfg.Sampler.Units), unitsSource()})
+		}
+		hub := newStreamHub(every, metrics.snapshot)
+		mux.Handle("/api/v1/stream", hub)
 
-		// Serve static dashboard
-		fs := http.FileServer(http.Dir("./dashboard"))
-		http.Handle("/", fs)
+		// Serve the embedded dashboard, or a directory during development
+		if dir := flagOr(cmd, "dashboard-dir", dashboardDir, cfg.Serve.DashboardDir); dir != "" {
+			mux.Handle("/", newAssetHandler(os.DirFS(dir), "no-store"))
+		} else {
+			mux.Handle("/", newAssetHandler(dashboard.FS, "no-cache"))
+		}
+
+		authCfg := cfg.Auth
+		if !authCfg.Enabled() {
+			// Without any credentials configured everything stays open
+			authCfg.Anonymous = roleAdmin
+		}
+		audit, err := openAuditLog(authCfg.AuditLog)
+		if err != nil {
+			color.Red(" Failed to open audit log: %v", err)
+			os.Exit(1)
+		}
+		auth := newAuthenticator(authCfg, audit)
+
+		srv := &http.Server{
+			Handler:           auth.middleware(mux),
+			ReadHeaderTimeout: flagOr(cmd, "read-timeout", readTimeout, time.Duration(cfg.Serve.ReadTimeout)),
+			ReadTimeout:       flagOr(cmd, "read-timeout", readTimeout, time.Duration(cfg.Serve.ReadTimeout)),
+			WriteTimeout:      flagOr(cmd, "write-timeout", writeTimeout, time.Duration(cfg.Serve.WriteTimeout)),
+			IdleTimeout:       time.Duration(cfg.Serve.IdleTimeout),
+		}
+		scheme := "http"
+		if certFile != "" {
+			certs, err := newCertReloader(certFile, keyFile)
+			if err != nil {
+				color.Red(" Failed to load TLS certificate: %v", err)
+				os.Exit(1)
+			}
+			srv.TLSConfig = &tls.Config{GetCertificate: certs.GetCertificate, MinVersion: tls.VersionTLS12}
+			scheme = "https"
+		}
+		if authCfg.ClientCA != "" {
+			if srv.TLSConfig == nil {
+				color.Red(" auth.client_ca requires --tls-cert and --tls-key")
+				os.Exit(1)
+			}
+			pool, err := loadCertPool(authCfg.ClientCA)
+			if err != nil {
+				color.Red(" Failed to load client CA: %v", err)
+				os.Exit(1)
+			}
+			// Clients without a certificate may still use tokens or passwords
+			srv.TLSConfig.ClientCAs = pool
+			srv.TLSConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+
+		// Open streams would otherwise hold Shutdown until its timeout
+		srv.RegisterOnShutdown(hub.close)
+
+		ln, err := listen(addr)
+		if err != nil {
+			color.Red(" Failed to start server: %v", err)
+			os.Exit(1)
+		}
+
+		// Collect once before serving, then keep every source fresh in the
+		// background; handlers only read the latest snapshot
+		stopHistory := make(chan struct{})
+		historyDone := make(chan struct{})
+		metrics.start(stopHistory)
+		go collectHistoryWorker(stopHistory, historyDone)
+		go hub.run()
 
 		// Start server
-		addr := fmt.Sprintf("127.0.0.1:%d", port)
 		green := color.New(color.FgGreen).SprintFunc()
 		cyan := color.New(color.FgCyan).SprintFunc()
 
 		fmt.Printf("\n🚀 %s Vigil Metrics Dashboard\n", green("Starting"))
-		fmt.Printf("📡 %s http://localhost:%d\n", cyan("Dashboard URL:"), port)
-		fmt.Printf("📊 %s http://localhost:%d/api/v1/metrics\n", cyan("Live Metrics:"), port)
-		fmt.Printf("📈 %s http://localhost:%d/api/v1/metrics/history\n", cyan("History API:"), port)
-		fmt.Printf("💻 %s http://localhost:%d/api/v1/system/info\n", cyan("System Info:"), port)
-		fmt.Printf("⚙️  %s http://localhost:%d/api/v1/processes\n", cyan("Process List:"), port)
+		if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+			fmt.Printf("📡 %s %s (curl --unix-socket %s %s://localhost/api/v1/metrics)\n", cyan("Unix socket:"), path, path, scheme)
+		} else {
+			base := scheme + "://" + displayAddr(addr)
+			fmt.Printf("📡 %s %s\n", cyan("Dashboard URL:"), base)
+			fmt.Printf("📊 %s %s/api/v1/metrics\n", cyan("Live Metrics:"), base)
+			fmt.Printf("📈 %s %s/api/v1/metrics/history\n", cyan("History API:"), base)
+			fmt.Printf("📺 %s %s/api/v1/stream\n", cyan("Live Stream:"), base)
+			fmt.Printf("💻 %s %s/api/v1/system/info\n", cyan("System Info:"), base)
+			fmt.Printf("⚙️  %s %s/api/v1/processes\n", cyan("Process List:"), base)
+		}
+		if !cfg.Auth.Enabled() && !isLoopback(addr) {
+			color.Yellow("⚠️  No auth configured: anyone who can reach %s can list processes", addr)
+		}
 		fmt.Printf(" %s\n\n", cyan("Use Ctrl+C to stop"))
 
-		if err := http.ListenAndServe(addr, nil); err != nil {
-			color.Red(" Failed to start server: %v", err)
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+		serveErr := make(chan error, 1)
+		go func() {
+			if srv.TLSConfig != nil {
+				serveErr <- srv.ServeTLS(ln, "", "")
+			} else {
+				serveErr <- srv.Serve(ln)
+			}
+		}()
+
+		select {
+		case err := <-serveErr:
+			color.Red(" Server failed: %v", err)
 			os.Exit(1)
+		case sig := <-stop:
+			fmt.Printf("\n🛑 %s %s, shutting down\n", cyan("Received"), sig)
 		}
-	},
-}
+		signal.Stop(stop)
 
-func collectMetrics() map[string]interface{} {
-	// CPU Metrics
-	cpuPercent, _ := cpu.Percent(0, false)
-	cpuCount, _ := cpu.Counts(true)
-	cpuFreq, _ := cpu.Info()
-	var cpuFreqStr string
-	if len(cpuFreq) > 0 {
-		cpuFreqStr = fmt.Sprintf("%.2f GHz", cpuFreq[0].Mhz/1000)
-	}
-
-	// Memory Metrics
-	memInfo, _ := mem.VirtualMemory()
-	swapInfo, _ := mem.SwapMemory()
-
-	// Disk Metrics
-	diskInfo, _ := disk.Usage("/")
-	diskIO, _ := disk.IOCounters()
-
-	// Network Metrics
-	netIO, _ := net.IOCounters(false)
-	var netStats map[string]interface{}
-	if len(netIO) > 0 {
-		netStats = map[string]interface{}{
-			"bytes_sent":   netIO[0].BytesSent,
-			"bytes_recv":   netIO[0].BytesRecv,
-			"packets_sent": netIO[0].PacketsSent,
-			"packets_recv": netIO[0].PacketsRecv,
-			"err_in":       netIO[0].Errin,
-			"err_out":      netIO[0].Errout,
-			"drop_in":      netIO[0].Dropin,
-			"drop_out":     netIO[0].Dropout,
-		}
-	}
-
-	// Host Info
-	hostInfo, _ := host.Info()
-	uptime, _ := host.Uptime()
-
-	// Process Count
-	processes, _ := process.Processes()
-	processCount := len(processes)
-
-	// Go Runtime Metrics
-	var m runtime.MemStats
-	runtime.ReadMemStats(&m)
-
-	return map[string]interface{}{
-		"timestamp": time.Now().UTC(),
-		"host": map[string]interface{}{
-			"hostname":         hostInfo.Hostname,
-			"os":               hostInfo.OS,
-			"platform":         hostInfo.Platform,
-			"platform_family":  hostInfo.PlatformFamily,
-			"platform_version": hostInfo.PlatformVersion,
-			"kernel_version":   hostInfo.KernelVersion,
-			"uptime_seconds":   uptime,
-		},
-		"cpu": map[string]interface{}{
-			"percent":        cpuPercent[0],
-			"cores_physical": cpuCount,
-			"cores_logical":  runtime.NumCPU(),
-			"frequency":      cpuFreqStr,
-			"load_average":   getLoadAverage(),
-		},
-		"memory": map[string]interface{}{
-			"total":        memInfo.Total,
-			"available":    memInfo.Available,
-			"used":         memInfo.Used,
-			"free":         memInfo.Free,
-			"percent":      memInfo.UsedPercent,
-			"swap_total":   swapInfo.Total,
-			"swap_used":    swapInfo.Used,
-			"swap_percent": swapInfo.UsedPercent,
-			"cached":       memInfo.Cached,
-			"buffers":      memInfo.Buffers,
-		},
-		"disk": map[string]interface{}{
-			"total":          diskInfo.Total,
-			"free":           diskInfo.Free,
-			"used":           diskInfo.Used,
-			"percent":        diskInfo.UsedPercent,
-			"inodes_total":   diskInfo.InodesTotal,
-			"inodes_used":    diskInfo.InodesUsed,
-			"inodes_free":    diskInfo.InodesFree,
-			"inodes_percent": diskInfo.InodesUsedPercent,
-			"io_read_bytes":  getDiskIOBytes(diskIO, "read"),
-			"io_write_bytes": getDiskIOBytes(diskIO, "write"),
-		},
-		"network": netStats,
-		"system": map[string]interface{}{
-			"goroutines":    runtime.NumGoroutine(),
-			"cgo_calls":     runtime.NumCgoCall(),
-			"process_count": processCount,
-			"go_mem_alloc":  m.Alloc,
-			"go_mem_sys":    m.Sys,
-			"go_mem_heap":   m.HeapAlloc,
-			"go_mem_stack":  m.StackInuse,
-			"go_gc_count":   m.NumGC,
-			"go_gc_pause":   m.PauseTotalNs,
-		},
-		"alerts": checkAlerts(cpuPercent[0], memInfo.UsedPercent, diskInfo.UsedPercent),
-	}
+		// Let in-flight requests finish, then flush history
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Serve.ShutdownTimeout))
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			color.Yellow(" Forced shutdown: %v", err)
+		}
+		close(stopHistory)
+		<-historyDone
+		if historyFile != "" {
+			if err := saveHistory(historyFile); err != nil {
+				color.Red(" Failed to save history: %v", err)
+				os.Exit(1)
+			}
+			fmt.Printf("💾 %s %s\n", cyan("History saved to"), historyFile)
+		}
+	},
 }
 
 func getLoadAverage() []float64 {
@@ -197,52 +263,71 @@ func getDiskIOBytes(io map[string]disk.IOCountersStat, op string) uint64 {
 	return total
 }
 
-func checkAlerts(cpu, mem, disk float64) []map[string]interface{} {
+// alertMetrics are the metrics alert rules can watch, in the order their
+// alerts are listed. Battery alerts when the charge drops below a rule.
+var alertMetrics = []struct {
+	name, label, unit string
+	below             bool
+}{
+	{"cpu", "CPU usage", "%", false},
+	{"memory", "Memory usage", "%", false},
+	{"swap", "Swap usage", "%", false},
+	{"disk", "Disk usage", "%", false},
+	{"temperature", "Temperature", "°C", false},
+	{"throttled", "Throttling", "", false},
+	{"battery", "Battery", "%", true},
+	{"days_until_full", "Disk full in", " days", true},
+}
+
+// checkAlerts applies the configured alert rules to the current values.
+// Metrics missing from values, e.g. battery on a server, never alert. Each
+// metric raises at most one alert: the matching rule with the most extreme
+// threshold.
+func checkAlerts(values map[string]float64) []map[string]interface{} {
 	var alerts []map[string]interface{}
 	now := time.Now()
 
-	if cpu > 90 {
-		alerts = append(alerts, map[string]interface{}{
-			"level":   "critical",
-			"metric":  "cpu",
-			"message": fmt.Sprintf("CPU usage critical: %.1f%%", cpu),
-			"value":   cpu,
-			"time":    now,
-		})
-	} else if cpu > 75 {
-		alerts = append(alerts, map[string]interface{}{
-			"level":   "warning",
-			"metric":  "cpu",
-			"message": fmt.Sprintf("CPU usage high: %.1f%%", cpu),
-			"value":   cpu,
-			"time":    now,
-		})
-	}
-
-	if mem > 90 {
-		alerts = append(alerts, map[string]interface{}{
-			"level":   "critical",
-			"metric":  "memory",
-			"message": fmt.Sprintf("Memory usage critical: %.1f%%", mem),
-			"value":   mem,
-			"time":    now,
-		})
-	} else if mem > 80 {
-		alerts = append(alerts, map[string]interface{}{
-			"level":   "warning",
-			"metric":  "memory",
-			"message": fmt.Sprintf("Memory usage high: %.1f%%", mem),
-			"value":   mem,
-			"time":    now,
-		})
-	}
-
-	if disk > 90 {
+	for _, metric := range alertMetrics {
+		value, ok := values[metric.name]
+		if !ok {
+			continue
+		}
+		var match *config.AlertRule
+		for i, rule := range cfg.Alerts {
+			if rule.Metric != metric.name {
+				continue
+			}
+			if metric.below {
+				if value < rule.Below && (match == nil || rule.Below < match.Below) {
+					match = &cfg.Alerts[i]
+				}
+			} else if value > rule.Above && (match == nil || rule.Above > match.Above) {
+				match = &cfg.Alerts[i]
+			}
+		}
+		if match == nil {
+			continue
+		}
+		word := "high"
+		switch {
+		case match.Level == "critical":
+			word = "critical"
+		case metric.below:
+			word = "low"
+		}
+		message := fmt.Sprintf("%s %s: %.1f%s", metric.label, word, value, metric.unit)
+		switch metric.name {
+		case "throttled":
+			message = "CPU throttled"
+		case "days_until_full":
+			message = fmt.Sprintf("Disk full in %.1f days", value)
+		}
 		alerts = append(alerts, map[string]interface{}{
-			"level":   "critical",
-			"metric":  "disk",
-			"message": fmt.Sprintf("Disk usage critical: %.1f%%", disk),
-			"value":   disk,
+			"level":   match.Level,
+			"metric":  metric.name,
+			"message": message,
+			"value":   value,
+			"unit":    metric.unit,
 			"time":    now,
 		})
 	}
@@ -250,14 +335,23 @@ func checkAlerts(cpu, mem, disk float64) []map[string]interface{} {
 	return alerts
 }
 
-func collectHistoryWorker() {
-	ticker := time.NewTicker(5 * time.Second)
+// collectHistoryWorker records a history point every history.interval
+// until stop is closed, then closes done
+func collectHistoryWorker(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(time.Duration(cfg.History.Interval))
 	defer ticker.Stop()
 
-	for range ticker.C {
-		metrics := collectMetrics()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		point := metrics.snapshot()
+		exportMetrics(point)
 		historyMutex.Lock()
-		metricsHistory = append(metricsHistory, metrics)
+		metricsHistory = append(metricsHistory, point)
 		if len(metricsHistory) > historyLimit {
 			metricsHistory = metricsHistory[len(metricsHistory)-historyLimit:]
 		}
@@ -267,10 +361,9 @@ func collectHistoryWorker() {
 
 // API Handlers
 func handleMetrics(w http.ResponseWriter, r *http.Request) {
-	metrics := collectMetrics()
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Cache-Control", "no-cache")
-	json.NewEncoder(w).Encode(metrics)
+	json.NewEncoder(w).Encode(metrics.snapshot())
 }
 
 func handleMetricsHistory(w http.ResponseWriter, r *http.Request) {
@@ -295,53 +388,147 @@ func handleMetricsHistory(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// handleSystemInfo serves the host details the sampler read last, with
+// the uptime brought up to date
 func handleSystemInfo(w http.ResponseWriter, r *http.Request) {
-	info, _ := host.Info()
+	value, status, _ := metrics.detail("system_info")
+	info, ok := value["info"].(host.InfoStat)
+	if !ok {
+		writeDetailError(w, "system info", status)
+		return
+	}
+	if now := uint64(time.Now().Unix()); now > info.BootTime {
+		info.Uptime = now - info.BootTime
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(info)
 }
 
+// handleProcesses serves the process list the sampler read last
 func handleProcesses(w http.ResponseWriter, r *http.Request) {
-	processes, err := process.Processes()
-	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"error": fmt.Sprintf("Failed to get processes: %v", err),
-		})
+	value, status, _ := metrics.detail("process_list")
+	processList, ok := value["processes"].([]map[string]interface{})
+	if !ok {
+		writeDetailError(w, "processes", status)
 		return
 	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(processList)
+}
 
-	var processList []map[string]interface{}
+// writeDetailError reports a detail source that has never been collected
+// successfully
+func writeDetailError(w http.ResponseWriter, what string, status map[string]interface{}) {
+	reason, _ := status["error"].(string)
+	if reason == "" {
+		reason = "not collected yet"
+	}
+	writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get %s: %s", what, reason))
+}
 
-	for _, p := range processes {
-		name, _ := p.Name()
-		mem, _ := p.MemoryInfo()
-		cpu, _ := p.CPUPercent()
+// handleContainers serves the latest containers reading with its
+// freshness, like the sources section of /api/v1/metrics
+func handleContainers(w http.ResponseWriter, r *http.Request) {
+	value, status, ok := metrics.detail("containers")
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "no Docker or Podman socket found (set containers.socket)")
+		return
+	}
+	response := map[string]interface{}{"containers": []interface{}{}}
+	for k, v := range value {
+		response[k] = v
+	}
+	for k, v := range status {
+		response[k] = v
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(response)
+}
 
-		// Handle nil memory info
-		var rss, vms uint64
-		if mem != nil {
-			rss = mem.RSS
-			vms = mem.VMS
+// handleUnits serves the latest systemd units reading. ?failed=true,
+// ?min_cpu=<percent> and ?min_mem=<size> filter it like `vigil units`.
+func handleUnits(w http.ResponseWriter, r *http.Request) {
+	value, status, ok := metrics.detail("units")
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "this host was not booted with systemd")
+		return
+	}
+	q := r.URL.Query()
+	var filter unitFilter
+	filter.failed = q.Get("failed") == "true"
+	if v := q.Get("min_cpu"); v != "" {
+		var err error
+		if filter.minCPU, err = strconv.ParseFloat(v, 64); err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid min_cpu %q", v))
+			return
+		}
+	}
+	if v := q.Get("min_mem"); v != "" {
+		size, err := parseSize(v)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
 		}
+		filter.minMem = size
+	}
 
-		processList = append(processList, map[string]interface{}{
-			"pid":     p.Pid,
-			"name":    name,
-			"cpu":     cpu,
-			"mem_rss": rss,
-			"mem_vms": vms,
-		})
+	response := map[string]interface{}{"units": []format.UnitStat{}}
+	for k, v := range value {
+		response[k] = v
+	}
+	if units, ok := value["units"].([]format.UnitStat); ok {
+		response["units"] = filter.apply(units)
 	}
+	for k, v := range status {
+		response[k] = v
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(response)
+}
 
+// handleIO serves the latest per-device disk activity. Devices in
+// io.exclude are left out unless ?all=true; ?device=<glob> (repeatable)
+// selects devices like `vigil io --device`.
+func handleIO(w http.ResponseWriter, r *http.Request) {
+	value, status, _ := metrics.detail("io")
+	q := r.URL.Query()
+	filter := ioFilter(q["device"], q.Get("all") == "true")
+
+	response := map[string]interface{}{"devices": []format.DeviceIOStat{}}
+	for k, v := range value {
+		response[k] = v
+	}
+	if devices, ok := value["devices"].([]format.DeviceIOStat); ok {
+		kept := []format.DeviceIOStat{}
+		for _, d := range devices {
+			if filter.Keep(d.Device) {
+				kept = append(kept, d)
+			}
+		}
+		response["devices"] = kept
+	}
+	for k, v := range status {
+		response[k] = v
+	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(processList)
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(response)
 }
 
+// handleNetworkStats serves the per-interface counters the sampler read
+// last
 func handleNetworkStats(w http.ResponseWriter, r *http.Request) {
-	stats, _ := net.IOCounters(true)
+	value, status, _ := metrics.detail("interfaces")
+	stats, ok := value["interfaces"].([]net.IOCountersStat)
+	if !ok {
+		writeDetailError(w, "network stats", status)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
 	json.NewEncoder(w).Encode(stats)
 }
 
@@ -357,8 +544,179 @@ func handleHealthCheck(w http.ResponseWriter, r *http.Request) {
 
 var startTime = time.Now()
 
+// exportMetrics sends a history point to every configured exporter
+func exportMetrics(metrics map[string]interface{}) {
+	for _, e := range cfg.Exporters {
+		switch e.Type {
+		case "ndjson":
+			if err := appendJSONLine(e.Path, metrics); err != nil {
+				color.Red(" Export to %s failed: %v", e.Path, err)
+			}
+		}
+	}
+}
+
+func appendJSONLine(path string, v interface{}) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(v); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// listen opens addr, which is host:port or unix:/path/to/socket
+func listen(addr string) (stdnet.Listener, error) {
+	path, ok := strings.CutPrefix(addr, "unix:")
+	if !ok {
+		return stdnet.Listen("tcp", addr)
+	}
+	// A socket left behind by a crashed server would block the bind, but
+	// one that still accepts connections belongs to a running server
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if c, err := stdnet.Dial("unix", path); err == nil {
+			c.Close()
+			return nil, fmt.Errorf("%s is in use", path)
+		}
+		os.Remove(path)
+	}
+	return stdnet.Listen("unix", path)
+}
+
+// loadHistory restores history saved by an earlier shutdown. A missing
+// file is not an error.
+func loadHistory(path string) error {
+	history, err := readHistory(path, historyLimit)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	historyMutex.Lock()
+	metricsHistory = history
+	historyMutex.Unlock()
+	seedDiskTrend(diskPoints(history), time.Duration(cfg.Forecast.Window))
+	return nil
+}
+
+// readHistory reads the last limit history points from a file saved on
+// shutdown or from an ndjson exporter's file
+func readHistory(path string, limit int) ([]map[string]interface{}, error) {
+	var history []map[string]interface{}
+	err := eachHistoryPoint(path, func(point map[string]interface{}) {
+		// Dropped points are freed once append moves the slice
+		history = append(history, point)
+		if len(history) > limit {
+			history = history[1:]
+		}
+	})
+	return history, err
+}
+
+// eachHistoryPoint calls fn with every point of a history file, a JSON
+// array, or an ndjson exporter's file, one point per line. The file is
+// streamed: an exporter's file grows without bound.
+func eachHistoryPoint(path string, fn func(map[string]interface{})) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	br := bufio.NewReader(f)
+	array := false
+	for {
+		b, err := br.ReadByte()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !unicode.IsSpace(rune(b)) {
+			array = b == '['
+			br.UnreadByte()
+			break
+		}
+	}
+
+	dec := json.NewDecoder(br)
+	if array {
+		if _, err := dec.Token(); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	for !array || dec.More() {
+		var point map[string]interface{}
+		err := dec.Decode(&point)
+		if !array && errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		fn(point)
+	}
+	return nil
+}
+
+// saveHistory writes the history atomically so a crash mid-write keeps the
+// previous file
+func saveHistory(path string) error {
+	historyMutex.RLock()
+	data, err := json.Marshal(metricsHistory)
+	historyMutex.RUnlock()
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// isLoopback reports whether addr is only reachable from this host
+func isLoopback(addr string) bool {
+	if strings.HasPrefix(addr, "unix:") {
+		return true
+	}
+	host, _, err := stdnet.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := stdnet.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// displayAddr turns a listen address into one a browser can open
+func displayAddr(addr string) string {
+	host, port, err := stdnet.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	if host == "" || host == "127.0.0.1" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return stdnet.JoinHostPort(host, port)
+}
+
 func init() {
-	serveCmd.Flags().IntVarP(&port, "port", "p", 8080, "Port for dashboard")
+	serveCmd.Flags().IntVarP(&port, "port", "p", 8080, "Port for dashboard on 127.0.0.1 (overrides serve.listen)")
+	serveCmd.Flags().StringVar(&listenAddr, "listen", "127.0.0.1:8080", "Address to listen on: host:port or unix:/path/to/socket")
+	serveCmd.Flags().StringVar(&tlsCert, "tls-cert", "", "TLS certificate file (reloaded when it changes)")
+	serveCmd.Flags().StringVar(&tlsKey, "tls-key", "", "TLS private key file")
+	serveCmd.Flags().StringVar(&dashboardDir, "dashboard-dir", "", "Serve dashboard files from this directory instead of the embedded copy")
+	serveCmd.Flags().DurationVar(&readTimeout, "read-timeout", 10*time.Second, "Maximum time to read a request")
+	serveCmd.Flags().DurationVar(&writeTimeout, "write-timeout", 30*time.Second, "Maximum time to write a response")
+	serveCmd.Flags().DurationVar(&streamInterval, "stream-interval", time.Second, "How often /api/v1/stream collects metrics (the fastest rate a client can ask for)")
 	serveCmd.Flags().IntVar(&historyLimit, "history-limit", 1000, "Maximum number of historical data points")
+	serveCmd.Flags().StringVar(&historyFile, "history-file", "", "Load history from this file at startup and save it on shutdown")
 	rootCmd.AddCommand(serveCmd)
 }
@@ -0,0 +1,215 @@
+// cmd/status.go
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/sahil3982/vigil/internal/cgroup"
+	"github.com/sahil3982/vigil/internal/format"
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/host"
+	"github.com/shirou/gopsutil/v3/load"
+	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statusInterval time.Duration
+	statusTop      int
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show a host overview: CPU, memory, swap, disks, load, network, sensors and top processes",
+	Run:   runStatus,
+}
+
+func runStatus(cmd *cobra.Command, args []string) {
+	interval := flagOr(cmd, "interval", statusInterval, time.Duration(cfg.Status.Interval))
+	top := flagOr(cmd, "top", statusTop, cfg.Status.Top)
+	if top < 0 {
+		fmt.Fprintln(os.Stderr, "✗ --top cannot be negative")
+		os.Exit(1)
+	}
+	stat, errs := collectSnapshot(scopedCgroup(), interval, top)
+	f := newFormatter()
+	if err := f.Snapshot(os.Stdout, stat); err != nil {
+		failOutput(err)
+	}
+	if len(errs) > 0 {
+		reportSourceErrors(errs)
+		os.Exit(exitPartial)
+	}
+}
+
+// collectSnapshot reads everything in one pass. Rates (CPU, network and
+// per-process CPU) are measured over interval; top limits the process list.
+// A source that fails is left empty and returned as an error; the others
+// are still collected. With a cgroup, CPU, memory and processes are the
+// cgroup's.
+func collectSnapshot(g *cgroup.Group, interval time.Duration, top int) (format.Snapshot, []*sourceError) {
+	snap := format.Snapshot{Time: time.Now().UTC()}
+	var errs []*sourceError
+	fail := func(source string, err error) {
+		errs = append(errs, &sourceError{Source: source, Err: err})
+		snap.Errors = append(snap.Errors, format.SourceError{Source: source, Error: err.Error()})
+	}
+
+	// First readings for everything measured as a rate
+	netBefore, netErr := net.IOCounters(true)
+	procs, err := scopedProcesses(g)
+	if err != nil {
+		fail("processes", err)
+	}
+	for _, p := range procs {
+		p.Percent(0)
+	}
+
+	start := time.Now()
+	if usage, err := newCPUSampler(g).Measure(interval); err == nil {
+		snap.CPU = cpuStat(usage)
+	} else {
+		fail("cpu", err)
+	}
+	elapsed := time.Since(start).Seconds()
+	if g == nil {
+		snap.CPU.Cores, _ = cpu.Counts(true)
+	} else {
+		snap.Scope = scopeCgroup
+		if p, err := g.Pids(); err == nil {
+			snap.Pids = &format.PidsStat{Current: p.Current, Limit: p.Limit}
+		}
+	}
+
+	if info, err := host.Info(); err == nil {
+		snap.Hostname = info.Hostname
+		snap.UptimeSeconds = info.Uptime
+	} else {
+		fail("host", err)
+	}
+	if l, err := load.Avg(); err == nil {
+		snap.Load = &format.LoadStat{Load1: l.Load1, Load5: l.Load5, Load15: l.Load15}
+	}
+	if snap.Mem, err = memStat(g); err != nil {
+		fail("memory", err)
+	}
+	if s, err := mem.SwapMemory(); err == nil {
+		snap.Swap = format.SwapStat{TotalBytes: s.Total, UsedBytes: s.Used, UsedPercent: s.UsedPercent}
+	} else {
+		fail("swap", err)
+	}
+	if snap.Sensors, err = readSensors(); err != nil {
+		fail("sensors", err)
+	}
+
+	if snap.Disks, err = collectDisks(); err != nil {
+		fail("disk", err)
+	}
+	if netErr == nil {
+		snap.Net, netErr = netRates(netBefore, elapsed)
+	}
+	if netErr != nil {
+		snap.Net = []format.NetRate{}
+		fail("network", netErr)
+	}
+	snap.Processes = topProcesses(procs, top)
+	return snap, errs
+}
+
+// collectDisks returns the usage of every mounted device, once per device
+func collectDisks() ([]format.DiskStat, error) {
+	disks := []format.DiskStat{}
+	parts, err := disk.Partitions(false)
+	if err != nil {
+		return disks, err
+	}
+	seen := map[string]bool{}
+	for _, part := range parts {
+		if seen[part.Device] {
+			continue
+		}
+		usage, err := disk.Usage(part.Mountpoint)
+		if err != nil || usage.Total == 0 {
+			continue
+		}
+		seen[part.Device] = true
+		disks = append(disks, format.DiskStat{
+			Path:        usage.Path,
+			TotalBytes:  usage.Total,
+			UsedBytes:   usage.Used,
+			FreeBytes:   usage.Free,
+			UsedPercent: usage.UsedPercent,
+		})
+	}
+	return disks, nil
+}
+
+// netRates compares interface counters with an earlier reading. Loopback
+// and interfaces that never carried traffic are left out.
+func netRates(before []net.IOCountersStat, elapsed float64) ([]format.NetRate, error) {
+	rates := []format.NetRate{}
+	after, err := net.IOCounters(true)
+	if err != nil || elapsed <= 0 {
+		return rates, err
+	}
+	prev := make(map[string]net.IOCountersStat, len(before))
+	for _, c := range before {
+		prev[c.Name] = c
+	}
+	for _, c := range after {
+		p, ok := prev[c.Name]
+		if !ok || c.Name == "lo" || c.BytesRecv+c.BytesSent == 0 {
+			continue
+		}
+		rates = append(rates, format.NetRate{
+			Interface:     c.Name,
+			RxBytesPerSec: float64(counterDelta(p.BytesRecv, c.BytesRecv)) / elapsed,
+			TxBytesPerSec: float64(counterDelta(p.BytesSent, c.BytesSent)) / elapsed,
+		})
+	}
+	return rates, nil
+}
+
+// topProcesses returns the n busiest processes by CPU since their first
+// Percent call, breaking ties by RSS
+func topProcesses(procs []*process.Process, n int) []format.ProcessStat {
+	stats := []format.ProcessStat{}
+	for _, p := range procs {
+		cpuPercent, err := p.Percent(0)
+		if err != nil {
+			continue
+		}
+		s := format.ProcessStat{PID: p.Pid, CPUPercent: cpuPercent}
+		s.Name, _ = p.Name()
+		if m, err := p.MemoryInfo(); err == nil && m != nil {
+			s.RSSBytes = m.RSS
+		}
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].CPUPercent != stats[j].CPUPercent {
+			return stats[i].CPUPercent > stats[j].CPUPercent
+		}
+		return stats[i].RSSBytes > stats[j].RSSBytes
+	})
+	if len(stats) > n {
+		stats = stats[:n]
+	}
+	return stats
+}
+
+func init() {
+	statusCmd.Flags().DurationVar(&statusInterval, "interval", 500*time.Millisecond, "Interval over which CPU and network rates are measured")
+	statusCmd.Flags().IntVar(&statusTop, "top", 5, "Number of processes to list")
+	rootCmd.AddCommand(statusCmd)
+
+	// A bare `vigil` prints the status overview
+	rootCmd.Run = runStatus
+	rootCmd.Flags().AddFlagSet(statusCmd.Flags())
+}
@@ -0,0 +1,219 @@
+// cmd/stream.go
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// streamPing keeps idle connections open through proxies
+	streamPing = 15 * time.Second
+	// streamWriteTimeout disconnects clients that stop reading
+	streamWriteTimeout = 10 * time.Second
+	maxStreamInterval  = time.Hour
+)
+
+// streamHub reads the sampler's latest snapshot once per tick while anyone
+// is subscribed and hands the same snapshot to every subscriber
+type streamHub struct {
+	interval time.Duration
+	snapshot func() map[string]interface{}
+
+	mu     sync.Mutex
+	subs   map[*subscriber]struct{}
+	latest map[string]interface{}
+	closed bool
+	done   chan struct{}
+}
+
+// subscriber holds at most one pending snapshot. A client that falls
+// behind gets the newest snapshot instead of a growing backlog.
+type subscriber struct {
+	ch     chan map[string]interface{}
+	every  time.Duration
+	last   time.Time
+	fields []string
+}
+
+func newStreamHub(interval time.Duration, snapshot func() map[string]interface{}) *streamHub {
+	return &streamHub{
+		interval: interval,
+		snapshot: snapshot,
+		subs:     map[*subscriber]struct{}{},
+		done:     make(chan struct{}),
+	}
+}
+
+func (h *streamHub) run() {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.done:
+			return
+		case now := <-ticker.C:
+			h.mu.Lock()
+			idle := len(h.subs) == 0
+			h.mu.Unlock()
+			if idle {
+				continue
+			}
+
+			metrics := h.snapshot()
+			h.mu.Lock()
+			h.latest = metrics
+			for s := range h.subs {
+				// Half a tick of slack so ticker jitter does not skip a beat
+				if now.Sub(s.last) >= s.every-h.interval/2 {
+					s.last = now
+					s.offer(metrics)
+				}
+			}
+			h.mu.Unlock()
+		}
+	}
+}
+
+func (s *subscriber) offer(m map[string]interface{}) {
+	select {
+	case s.ch <- m:
+		return
+	default:
+	}
+	// Replace the snapshot the client has not picked up yet
+	select {
+	case <-s.ch:
+	default:
+	}
+	select {
+	case s.ch <- m:
+	default:
+	}
+}
+
+// subscribe registers a client. The latest snapshot, if any, is queued
+// right away so new clients do not wait a full interval.
+func (h *streamHub) subscribe(every time.Duration, fields []string) *subscriber {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil
+	}
+	s := &subscriber{ch: make(chan map[string]interface{}, 1), every: every, fields: fields}
+	if h.latest != nil {
+		s.last = time.Now()
+		s.offer(h.latest)
+	}
+	h.subs[s] = struct{}{}
+	return s
+}
+
+func (h *streamHub) unsubscribe(s *subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[s]; ok {
+		delete(h.subs, s)
+		close(s.ch)
+	}
+}
+
+// close ends every open stream; it is registered to run on server shutdown
+func (h *streamHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.closed = true
+	close(h.done)
+	for s := range h.subs {
+		delete(h.subs, s)
+		close(s.ch)
+	}
+}
+
+// ServeHTTP streams snapshots as Server-Sent Events. Query parameters:
+// interval (e.g. 5s, at least the hub interval) and fields (comma-separated
+// top-level keys such as cpu,memory).
+func (h *streamHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	every := h.interval
+	if v := r.URL.Query().Get("interval"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 || d > maxStreamInterval {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid interval %q", v))
+			return
+		}
+		every = max(d, h.interval)
+	}
+	var fields []string
+	if v := r.URL.Query().Get("fields"); v != "" {
+		fields = strings.Split(v, ",")
+	}
+
+	sub := h.subscribe(every, fields)
+	if sub == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "server is shutting down")
+		return
+	}
+	defer h.unsubscribe(sub)
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Stop nginx from buffering the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	write := func(format string, args ...interface{}) bool {
+		// A fresh deadline per event replaces the server's write timeout,
+		// which would otherwise end every stream after a few seconds
+		rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if _, err := fmt.Fprintf(w, format, args...); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+	if !write("retry: 3000\n\n") {
+		return
+	}
+
+	ping := time.NewTicker(streamPing)
+	defer ping.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case m, ok := <-sub.ch:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(selectFields(m, sub.fields))
+			if err != nil || !write("event: metrics\ndata: %s\n\n", data) {
+				return
+			}
+		case <-ping.C:
+			if !write(": ping\n\n") {
+				return
+			}
+		}
+	}
+}
+
+// selectFields keeps only the requested top-level keys (and the timestamp)
+func selectFields(m map[string]interface{}, fields []string) map[string]interface{} {
+	if len(fields) == 0 {
+		return m
+	}
+	out := map[string]interface{}{"timestamp": m["timestamp"]}
+	for _, f := range fields {
+		if v, ok := m[strings.TrimSpace(f)]; ok {
+			out[strings.TrimSpace(f)] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,82 @@
+// cmd/tlsreload.go
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// certReloader serves a certificate from disk and picks up replacements
+// (e.g. from certbot) without a restart. The files are checked at most once
+// per checkInterval; a pair that fails to load keeps the old certificate.
+type certReloader struct {
+	certPath, keyPath string
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+const checkInterval = 10 * time.Second
+
+func newCertReloader(certPath, keyPath string) (*certReloader, error) {
+	r := &certReloader{certPath: certPath, keyPath: keyPath}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *certReloader) load() error {
+	cert, err := tls.LoadX509KeyPair(r.certPath, r.keyPath)
+	if err != nil {
+		return err
+	}
+	r.cert = &cert
+	r.modTime = r.latestModTime()
+	return nil
+}
+
+// latestModTime is the newer of the two files' modification times, so
+// replacing either one triggers a reload
+func (r *certReloader) latestModTime() time.Time {
+	var latest time.Time
+	for _, p := range []string{r.certPath, r.keyPath} {
+		if info, err := os.Stat(p); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest
+}
+
+// GetCertificate implements tls.Config.GetCertificate
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if time.Since(r.lastCheck) >= checkInterval {
+		r.lastCheck = time.Now()
+		if !r.latestModTime().Equal(r.modTime) {
+			// Keep serving the old pair until both files are consistent
+			_ = r.load()
+		}
+	}
+	return r.cert, nil
+}
+
+// loadCertPool reads PEM certificates, e.g. the CA that signs client certs
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("%s: no PEM certificates found", path)
+	}
+	return pool, nil
+}
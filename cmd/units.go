@@ -0,0 +1,245 @@
+// cmd/units.go
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sahil3982/vigil/internal/cgroup"
+	"github.com/sahil3982/vigil/internal/format"
+	"github.com/sahil3982/vigil/internal/systemd"
+	"github.com/spf13/cobra"
+)
+
+const (
+	procRoot    = "/proc"
+	cgroupMount = "/sys/fs/cgroup"
+)
+
+var (
+	unitsInterval time.Duration
+	unitsFailed   bool
+	unitsMinCPU   float64
+	unitsMinMem   string
+	unitsSort     string
+	unitsTop      int
+)
+
+var unitsCmd = &cobra.Command{
+	Use:   "units",
+	Short: "Show CPU, memory, tasks and IO per systemd unit",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !slices.Contains([]string{"cpu", "mem", "io", "tasks", "name"}, unitsSort) {
+			fmt.Fprintln(os.Stderr, "✗ --sort must be cpu, mem, io, tasks or name")
+			os.Exit(1)
+		}
+		filter := unitFilter{failed: unitsFailed, minCPU: unitsMinCPU}
+		if unitsMinMem != "" {
+			size, err := parseSize(unitsMinMem)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "✗ --min-mem: %v\n", err)
+				os.Exit(1)
+			}
+			filter.minMem = size
+		}
+		if !systemd.Running() {
+			failSource("systemd", fmt.Errorf("this host was not booted with systemd: %w", fs.ErrNotExist))
+		}
+
+		list, errs, err := (&unitSampler{}).Measure(unitsInterval)
+		if err != nil {
+			failSource("units", err)
+		}
+		if filter.failed && len(errs) > 0 {
+			// Without unit states there is no telling which units failed
+			failSource(errs[0].Source, errs[0].Err)
+		}
+		list.Units = filter.apply(list.Units)
+		sortUnits(list.Units, unitsSort)
+		if unitsTop > 0 && len(list.Units) > unitsTop {
+			list.Units = list.Units[:unitsTop]
+		}
+
+		f := newFormatter()
+		if err := format.Write(f, os.Stdout, list); err != nil {
+			failOutput(err)
+		}
+		if len(errs) > 0 {
+			reportSourceErrors(errs)
+			os.Exit(exitPartial)
+		}
+	},
+}
+
+// unitFilter selects failed or busy units. A unit is kept when it matches
+// any of the filters that are set, so --failed --min-cpu 50 lists both
+// kinds of problem; with none set every unit is kept.
+type unitFilter struct {
+	failed bool
+	minCPU float64
+	minMem uint64
+}
+
+func (f unitFilter) apply(units []format.UnitStat) []format.UnitStat {
+	if !f.failed && f.minCPU <= 0 && f.minMem == 0 {
+		return units
+	}
+	kept := []format.UnitStat{}
+	for _, u := range units {
+		if (f.failed && u.Active == "failed") ||
+			(f.minCPU > 0 && u.CPUPercent >= f.minCPU) ||
+			(f.minMem > 0 && u.MemBytes >= f.minMem) {
+			kept = append(kept, u)
+		}
+	}
+	return kept
+}
+
+// sortUnits orders by cpu, mem, io, tasks or name; busiest first
+func sortUnits(us []format.UnitStat, by string) {
+	sort.SliceStable(us, func(i, j int) bool {
+		switch by {
+		case "mem":
+			return us[i].MemBytes > us[j].MemBytes
+		case "io":
+			return us[i].IOReadBytesPerSec+us[i].IOWriteBytesPerSec > us[j].IOReadBytesPerSec+us[j].IOWriteBytesPerSec
+		case "tasks":
+			return us[i].Tasks > us[j].Tasks
+		case "name":
+			return us[i].Name < us[j].Name
+		}
+		return us[i].CPUPercent > us[j].CPUPercent
+	})
+}
+
+// unitReading is the cumulative counters of one unit cgroup
+type unitReading struct {
+	cpuSeconds float64
+	io         cgroup.IO
+}
+
+// unitSampler reads every unit that has processes and reports its CPU and
+// IO rates since the previous reading
+type unitSampler struct {
+	prev map[string]unitReading
+	at   time.Time
+}
+
+// Measure reads the units twice, d apart
+func (s *unitSampler) Measure(d time.Duration) (format.UnitList, []*sourceError, error) {
+	if _, err := s.readCgroups(); err != nil {
+		return format.UnitList{}, nil, err
+	}
+	time.Sleep(d)
+	return s.Sample()
+}
+
+// Sample reports rates since the previous call. The first call has
+// nothing to compare with and measures over 200ms.
+func (s *unitSampler) Sample() (format.UnitList, []*sourceError, error) {
+	list := format.UnitList{Time: time.Now().UTC(), Units: []format.UnitStat{}}
+	if s.prev == nil {
+		if _, err := s.readCgroups(); err != nil {
+			return list, nil, err
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	stats, err := s.readCgroups()
+	if err != nil {
+		return list, nil, err
+	}
+
+	// Unit states come from systemd itself. Without them the cgroup
+	// readings are still listed.
+	var errs []*sourceError
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	units, err := systemd.ListUnits(ctx)
+	if err != nil {
+		errs = append(errs, &sourceError{Source: "systemd", Err: err})
+	}
+	byName := make(map[string]systemd.Unit, len(units))
+	for _, u := range units {
+		byName[u.Name] = u
+	}
+	for _, stat := range stats {
+		// Units of a user's service manager are not in the system list
+		if u, ok := byName[stat.Name]; ok && !strings.Contains(stat.Cgroup, "/user@") {
+			stat.Load, stat.Active, stat.Sub, stat.Description = u.Load, u.Active, u.Sub, u.Description
+			delete(byName, stat.Name)
+		}
+		list.Units = append(list.Units, stat)
+	}
+	// Failed units have no processes left, so no cgroup
+	for _, u := range units {
+		if _, ok := byName[u.Name]; ok && u.Failed() {
+			list.Units = append(list.Units, format.UnitStat{
+				Name: u.Name, Load: u.Load, Active: u.Active, Sub: u.Sub, Description: u.Description,
+			})
+		}
+	}
+	sort.Slice(list.Units, func(i, j int) bool { return list.Units[i].Name < list.Units[j].Name })
+	return list, errs, nil
+}
+
+// readCgroups reads the cgroup of every unit that has processes. Readings
+// a unit's cgroup lacks, e.g. with accounting turned off, are left zero.
+func (s *unitSampler) readCgroups() ([]format.UnitStat, error) {
+	paths, err := systemd.ProcessCgroups(procRoot)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	elapsed := now.Sub(s.at).Seconds()
+	readings := make(map[string]unitReading, len(paths))
+	stats := make([]format.UnitStat, 0, len(paths))
+	for path, pids := range paths {
+		name, _, _ := systemd.UnitOf(path)
+		stat := format.UnitStat{Name: name, Cgroup: path, Tasks: uint64(len(pids))}
+		g, err := cgroup.OpenPath(cgroupMount, path)
+		if err != nil {
+			stats = append(stats, stat)
+			continue
+		}
+		var r unitReading
+		if c, err := g.CPU(); err == nil {
+			r.cpuSeconds = c.UsageSeconds
+			stat.CPUSeconds = c.UsageSeconds
+		}
+		if m, err := g.Memory(); err == nil {
+			stat.MemBytes, stat.MemLimitBytes = m.UsageBytes, m.LimitBytes
+		}
+		if p, err := g.Pids(); err == nil {
+			stat.Tasks = p.Current
+		}
+		if io, err := g.IO(); err == nil {
+			r.io = io
+			stat.IOReadBytes, stat.IOWriteBytes = io.ReadBytes, io.WriteBytes
+		}
+		if prev, ok := s.prev[path]; ok && elapsed > 0 {
+			stat.CPUPercent = max(r.cpuSeconds-prev.cpuSeconds, 0) / elapsed * 100
+			stat.IOReadBytesPerSec = float64(counterDelta(prev.io.ReadBytes, r.io.ReadBytes)) / elapsed
+			stat.IOWriteBytesPerSec = float64(counterDelta(prev.io.WriteBytes, r.io.WriteBytes)) / elapsed
+		}
+		readings[path] = r
+		stats = append(stats, stat)
+	}
+	s.prev, s.at = readings, now
+	return stats, nil
+}
+
+func init() {
+	unitsCmd.Flags().DurationVar(&unitsInterval, "interval", 500*time.Millisecond, "Interval over which CPU and IO rates are measured")
+	unitsCmd.Flags().BoolVar(&unitsFailed, "failed", false, "Only list failed units (or, with --min-cpu or --min-mem, failed and busy ones)")
+	unitsCmd.Flags().Float64Var(&unitsMinCPU, "min-cpu", 0, "Only list units using at least this CPU percent (of one core)")
+	unitsCmd.Flags().StringVar(&unitsMinMem, "min-mem", "", "Only list units using at least this much memory (e.g. 512M)")
+	unitsCmd.Flags().StringVar(&unitsSort, "sort", "cpu", "Sort by cpu, mem, io, tasks or name")
+	unitsCmd.Flags().IntVar(&unitsTop, "top", 0, "Number of units to list (0 for all)")
+	rootCmd.AddCommand(unitsCmd)
+}
@@ -1,6 +1,10 @@
 package cmd
 
-import "strings"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 func barFor(value, max float64) string {
 	perc := value / max
@@ -10,4 +14,34 @@ func barFor(value, max float64) string {
 
 	bar := strings.Repeat("■", filled) + strings.Repeat("□", empty)
 	return "[" + bar + "]"
-}
\ No newline at end of file
+}
+
+// parseSize parses human sizes like "512M", "2G" or "1.5GB" into bytes.
+// Suffixes are binary (K = 1024).
+func parseSize(s string) (uint64, error) {
+	str := strings.ToUpper(strings.TrimSpace(s))
+	str = strings.TrimSuffix(strings.TrimSuffix(str, "IB"), "B")
+
+	mult := 1.0
+	if n := len(str); n > 0 {
+		switch str[n-1] {
+		case 'K':
+			mult = 1 << 10
+		case 'M':
+			mult = 1 << 20
+		case 'G':
+			mult = 1 << 30
+		case 'T':
+			mult = 1 << 40
+		}
+		if mult != 1 {
+			str = str[:n-1]
+		}
+	}
+
+	v, err := strconv.ParseFloat(str, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return uint64(v * mult), nil
+}
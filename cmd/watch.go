@@ -2,8 +2,11 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"time"
 
+	"github.com/sahil3982/vigil/internal/format"
+	"github.com/shirou/gopsutil/v3/disk"
 	"github.com/spf13/cobra"
 )
 
@@ -13,11 +16,64 @@ var watchCmd = &cobra.Command{
 	Use:   "watch",
 	Short: "Watch system stats every N seconds",
 	Run: func(cmd *cobra.Command, args []string) {
+		every := flagOr(cmd, "interval", time.Duration(interval)*time.Second, time.Duration(cfg.Watch.Interval))
+		if every <= 0 {
+			fmt.Fprintln(os.Stderr, "✗ --interval must be positive")
+			os.Exit(1)
+		}
+		f := newFormatter()
+		// One sampler for the whole run, so each CPU reading covers
+		// exactly the time since the previous one
+		group := scopedCgroup()
+		cpuSampler := newCPUSampler(group)
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
 		for {
-			// print CPU, mem, disk
-			fmt.Println("--- Snapshot ---")
-			// call your CPU/mem/disk logic here
-			time.Sleep(time.Duration(interval) * time.Second)
+			if !quiet {
+				fmt.Printf("--- %s ---\n", time.Now().Format("15:04:05"))
+			}
+			var errs []*sourceError
+			// Quiet mode prints one "cpu mem disk" line per tick, with "-"
+			// for a source that could not be read
+			write := func(source string, err error, print func() error) {
+				if quiet && source != "cpu" {
+					fmt.Print(" ")
+				}
+				if err != nil {
+					errs = append(errs, &sourceError{Source: source, Err: err})
+					if quiet {
+						fmt.Print("-")
+					}
+					return
+				}
+				if err := print(); err != nil {
+					failOutput(err)
+				}
+			}
+
+			usage, err := cpuSampler.Sample()
+			write("cpu", err, func() error {
+				return f.CPU(os.Stdout, cpuStat(usage))
+			})
+			m, err := memStat(group)
+			write("memory", err, func() error {
+				return f.Mem(os.Stdout, m)
+			})
+			d, err := disk.Usage("/")
+			write("disk", err, func() error {
+				return f.Disk(os.Stdout, format.DiskStat{
+					Path:        d.Path,
+					TotalBytes:  d.Total,
+					UsedBytes:   d.Used,
+					FreeBytes:   d.Free,
+					UsedPercent: d.UsedPercent,
+				})
+			})
+			if quiet {
+				fmt.Println()
+			}
+			reportSourceErrors(errs)
+			<-ticker.C
 		}
 	},
 }
@@ -0,0 +1,8 @@
+// Package dashboard holds the web UI served by `vigil serve`, embedded so
+// the binary works from any directory.
+package dashboard
+
+import "embed"
+
+//go:embed *.html *.js *.css
+var FS embed.FS
@@ -2,6 +2,7 @@
 const CONFIG = {
   api: {
     metrics: '/api/v1/metrics',
+    stream: '/api/v1/stream',
     history: '/api/v1/metrics/history',
     processes: '/api/v1/processes',
     system: '/api/v1/system/info',
@@ -30,7 +31,8 @@ let state = {
   networkStats: { prev: null, curr: null },
   cpuChart: null,
   memoryChart: null,
-  gaugeChart: null
+  gaugeChart: null,
+  stream: null
 };
 
 // DOM Elements
@@ -60,6 +62,9 @@ document.addEventListener('DOMContentLoaded', () => {
 function setupEventListeners() {
   elements.refreshInterval.addEventListener('change', (e) => {
     state.refreshInterval = parseInt(e.target.value);
+    if (state.stream) {
+      openStream();
+    }
   });
 
   elements.pauseBtn.addEventListener('click', () => {
@@ -78,85 +83,120 @@ function setupEventListeners() {
   document.getElementById('memoryHistoryRange').addEventListener('change', updateCharts);
 }
 
-// Metrics Polling
+// Metrics: pushed by the server over Server-Sent Events, with polling as a
+// fallback for browsers without EventSource
 function startMetricsPolling() {
+  if (window.EventSource) {
+    openStream();
+    return;
+  }
   updateMetrics();
   setInterval(() => {
     if (!state.isPaused) {
       updateMetrics();
     }
-  }, 1000);
+  }, state.refreshInterval);
+}
+
+// (Re)connect the stream at the selected refresh interval. EventSource
+// reconnects by itself after network errors.
+function openStream() {
+  if (state.stream) {
+    state.stream.close();
+  }
+  const url = `${CONFIG.api.stream}?interval=${state.refreshInterval / 1000}s`;
+  state.stream = new EventSource(url);
+  state.stream.addEventListener('metrics', (e) => {
+    if (!state.isPaused) {
+      applyMetrics(JSON.parse(e.data));
+    }
+  });
+  state.stream.onerror = () => {
+    showError('Lost connection to the metrics stream. Reconnecting...');
+  };
 }
 
 // Update Metrics
 async function updateMetrics() {
   try {
     const response = await fetch(CONFIG.api.metrics);
-    const data = await response.json();
-    
-    state.lastUpdate = new Date();
-    updateDashboard(data);
-    updateAlerts(data.alerts);
-    updateChartsData(data);
-    
-    // Update network rate calculations
-    updateNetworkStats(data.network);
-    
+    applyMetrics(await response.json());
   } catch (error) {
     console.error('Failed to fetch metrics:', error);
     showError('Failed to fetch metrics. Check server connection.');
   }
 }
 
+function applyMetrics(data) {
+  state.lastUpdate = new Date();
+  updateDashboard(data);
+  updateAlerts(data.alerts);
+  updateChartsData(data);
+
+  // Update network rate calculations
+  updateNetworkStats(data.network);
+}
+
 // Update Dashboard
 function updateDashboard(data) {
   // Timestamp
   elements.timestamp.textContent = `Last Updated: ${new Date(data.timestamp).toLocaleTimeString()}`;
   
   // Host Info
-  elements.hostname.textContent = data.host.hostname;
-  elements.uptime.textContent = `Uptime: ${formatUptime(data.host.uptime_seconds)}`;
+  if (data.host) {
+    elements.hostname.textContent = data.host.hostname;
+    elements.uptime.textContent = `Uptime: ${formatUptime(data.host.uptime_seconds)}`;
+  }
   
   // CPU
-  const cpuPct = data.cpu.percent;
-  document.getElementById('cpu-value').textContent = `${cpuPct.toFixed(1)}%`;
-  document.getElementById('cpu-cores').textContent = `Cores: ${data.cpu.cores_physical}`;
-  document.getElementById('cpu-freq').textContent = `Freq: ${data.cpu.frequency}`;
-  document.getElementById('load-1').textContent = data.cpu.load_average[0].toFixed(2);
-  document.getElementById('load-5').textContent = data.cpu.load_average[1].toFixed(2);
-  document.getElementById('load-15').textContent = data.cpu.load_average[2].toFixed(2);
+  if (data.cpu) {
+    const cpuPct = data.cpu.percent;
+    document.getElementById('cpu-value').textContent = `${cpuPct.toFixed(1)}%`;
+    document.getElementById('cpu-cores').textContent = `Cores: ${data.cpu.cores_physical}`;
+    document.getElementById('cpu-freq').textContent = `Freq: ${data.cpu.frequency}`;
+    document.getElementById('load-1').textContent = data.cpu.load_average[0].toFixed(2);
+    document.getElementById('load-5').textContent = data.cpu.load_average[1].toFixed(2);
+    document.getElementById('load-15').textContent = data.cpu.load_average[2].toFixed(2);
   
-  updateGauge('cpuGauge', cpuPct);
+    updateGauge('cpuGauge', cpuPct);
+  }
   
   // Memory
-  const memPct = data.memory.percent;
-  const memUsed = data.memory.used / 1e9;
-  const memTotal = data.memory.total / 1e9;
-  const memAvailable = data.memory.available / 1e9;
-  const memCached = data.memory.cached / 1e9;
+  if (data.memory) {
+    const memPct = data.memory.percent;
+    const memUsed = data.memory.used / 1e9;
+    const memTotal = data.memory.total / 1e9;
+    const memAvailable = data.memory.available / 1e9;
+    const memCached = data.memory.cached / 1e9;
   
-  document.getElementById('mem-value').textContent = `${memPct.toFixed(1)}%`;
-  document.getElementById('mem-bar').style.width = `${memPct}%`;
-  document.getElementById('mem-bar').style.background = getColorForMetric('memory', memPct);
-  document.getElementById('mem-total').textContent = `Total: ${memTotal.toFixed(1)} GB`;
-  document.getElementById('mem-used').textContent = `${memUsed.toFixed(1)} GB`;
-  document.getElementById('mem-available').textContent = `${memAvailable.toFixed(1)} GB`;
-  document.getElementById('mem-cached').textContent = `${memCached.toFixed(1)} GB`;
-  document.getElementById('mem-swap').textContent = `${data.memory.swap_percent.toFixed(1)}%`;
+    document.getElementById('mem-value').textContent = `${memPct.toFixed(1)}%`;
+    document.getElementById('mem-bar').style.width = `${memPct}%`;
+    document.getElementById('mem-bar').style.background = getColorForMetric('memory', memPct);
+    document.getElementById('mem-total').textContent = `Total: ${memTotal.toFixed(1)} GB`;
+    document.getElementById('mem-used').textContent = `${memUsed.toFixed(1)} GB`;
+    document.getElementById('mem-available').textContent = `${memAvailable.toFixed(1)} GB`;
+    document.getElementById('mem-cached').textContent = `${memCached.toFixed(1)} GB`;
+    document.getElementById('mem-swap').textContent = `${data.memory.swap_percent.toFixed(1)}%`;
+  }
   
   // Disk
-  const diskPct = data.disk.percent;
-  const diskUsed = data.disk.used / 1e9;
-  const diskTotal = data.disk.total / 1e9;
-  const diskFree = data.disk.free / 1e9;
+  if (data.disk) {
+    const diskPct = data.disk.percent;
+    const diskUsed = data.disk.used / 1e9;
+    const diskTotal = data.disk.total / 1e9;
+    const diskFree = data.disk.free / 1e9;
   
-  document.getElementById('disk-value').textContent = `${diskPct.toFixed(1)}%`;
-  document.getElementById('disk-bar').style.width = `${diskPct}%`;
-  document.getElementById('disk-bar').style.background = getColorForMetric('disk', diskPct);
-  document.getElementById('disk-used').textContent = `${diskUsed.toFixed(1)} GB`;
-  document.getElementById('disk-free').textContent = `${diskFree.toFixed(1)} GB`;
-  document.getElementById('disk-total').textContent = `${diskTotal.toFixed(1)} GB`;
-  document.getElementById('disk-inodes').textContent = `${data.disk.inodes_percent.toFixed(1)}%`;
+    document.getElementById('disk-value').textContent = `${diskPct.toFixed(1)}%`;
+    document.getElementById('disk-bar').style.width = `${diskPct}%`;
+    document.getElementById('disk-bar').style.background = getColorForMetric('disk', diskPct);
+    document.getElementById('disk-used').textContent = `${diskUsed.toFixed(1)} GB`;
+    document.getElementById('disk-free').textContent = `${diskFree.toFixed(1)} GB`;
+    document.getElementById('disk-total').textContent = `${diskTotal.toFixed(1)} GB`;
+    document.getElementById('disk-inodes').textContent = `${data.disk.inodes_percent.toFixed(1)}%`;
+    // null until there is enough history, or while usage is not growing
+    const fullIn = data.disk.days_until_full;
+    document.getElementById('disk-full-in').textContent = fullIn == null ? '--' : `${fullIn.toFixed(1)} days`;
+  }
   
   // Network
   if (data.network) {
@@ -172,10 +212,12 @@ function updateDashboard(data) {
   }
   
   // System Info
-  document.getElementById('system-os').textContent = `${data.host.os} ${data.host.platform_version}`;
-  document.getElementById('system-kernel').textContent = data.host.kernel_version;
-  document.getElementById('system-platform').textContent = data.host.platform;
-  document.getElementById('system-arch').textContent = data.host.platform_family;
+  if (data.host) {
+    document.getElementById('system-os').textContent = `${data.host.os} ${data.host.platform_version}`;
+    document.getElementById('system-kernel').textContent = data.host.kernel_version;
+    document.getElementById('system-platform').textContent = data.host.platform;
+    document.getElementById('system-arch').textContent = data.host.platform_family;
+  }
   
   // Go Runtime
   document.getElementById('go-goroutines').textContent = data.system.goroutines.toLocaleString();
@@ -226,7 +268,7 @@ function updateAlerts(alerts) {
         <strong>${alert.level.toUpperCase()}</strong>: ${alert.message}
         <div class="alert-time">${new Date(alert.time).toLocaleTimeString()}</div>
       </div>
-      <div class="alert-value">${alert.value.toFixed(1)}%</div>
+      <div class="alert-value">${alert.value.toFixed(1)}${alert.unit ?? '%'}</div>
     </div>
   `).join('');
 }
@@ -332,6 +374,9 @@ function initializeCharts() {
 }
 
 function updateChartsData(data) {
+  // A source that has never been collected leaves its section empty
+  if (!data.cpu || !data.memory || !data.disk) return;
+
   const now = new Date(data.timestamp);
   const timeLabel = now.toLocaleTimeString([], { hour: '2-digit', minute: '2-digit' });
   
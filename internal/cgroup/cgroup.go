@@ -0,0 +1,523 @@
+// internal/cgroup/cgroup.go
+package cgroup
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// v1Unlimited is the smallest value cgroup v1 uses to mean "no limit";
+// the kernel reports the page-aligned maximum int64
+const v1Unlimited = math.MaxInt64 &^ 0xfff
+
+// ErrRoot is returned for readings the v2 root cgroup does not have:
+// memory.current, memory.max and pids.current only exist below it. The
+// root cgroup is the whole host, so host-wide figures apply instead.
+var ErrRoot = errors.New("the root cgroup has no accounting of its own")
+
+// Group is the cgroup a process belongs to. Both the v2 unified hierarchy
+// and v1 per-controller hierarchies are supported.
+type Group struct {
+	Version int
+	// Root is set for the v2 root cgroup, which is the whole host
+	Root bool
+	// dirs maps a controller (memory, cpu, cpuacct, pids, blkio) to its
+	// directory; on v2 every controller shares one directory
+	dirs map[string]string
+}
+
+// Memory is the memory use of a cgroup. Limit is 0 when unlimited.
+type Memory struct {
+	UsageBytes      uint64
+	InactiveFile    uint64
+	LimitBytes      uint64
+	WorkingSetBytes uint64
+}
+
+// CPU is the cumulative CPU use of a cgroup. QuotaCores is 0 when the CPU
+// time is not limited.
+type CPU struct {
+	UsageSeconds     float64
+	QuotaCores       float64
+	Periods          uint64
+	ThrottledPeriods uint64
+	ThrottledSeconds float64
+}
+
+// Pids is the number of tasks in a cgroup. Limit is 0 when unlimited.
+type Pids struct {
+	Current uint64
+	Limit   uint64
+}
+
+// IO is the cumulative block IO of a cgroup, summed over devices
+type IO struct {
+	ReadBytes  uint64
+	WriteBytes uint64
+}
+
+// controllers are the ones Group reads; blkio is called io on v2
+var groupControllers = []string{"memory", "cpu", "cpuacct", "pids", "blkio"}
+
+// Self returns the cgroup of the running process
+func Self() (*Group, error) {
+	return Open("/proc/self/cgroup", "/proc/self/mountinfo", "/sys/fs/cgroup")
+}
+
+// Open reads a /proc/<pid>/cgroup file and resolves its paths under the
+// cgroupfs mounted at mount. The listed paths are relative to the root of
+// each hierarchy, and mountInfo (/proc/<pid>/mountinfo) tells which part
+// of the hierarchy is mounted: a v1 container without its own cgroup
+// namespace lists /docker/<id> but has that cgroup mounted at mount. A
+// listed path outside such a mount resolves to the mount itself. A listed
+// path missing from a mount of the whole hierarchy is an error: mount is
+// then another namespace's cgroupfs, and its root would be the wrong
+// cgroup. mountInfo may be missing, in which case every mount is taken to
+// be the whole hierarchy.
+func Open(procCgroup, mountInfo, mount string) (*Group, error) {
+	f, err := os.Open(procCgroup)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	g := &Group{dirs: map[string]string{}}
+	if _, err := os.Stat(filepath.Join(mount, "cgroup.controllers")); err == nil {
+		g.Version = 2
+	} else {
+		g.Version = 1
+	}
+	mounts, err := readMounts(mountInfo)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// hierarchy-ID:controller-list:path
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		controllers, path := parts[1], parts[2]
+		if g.Version == 2 {
+			if parts[0] == "0" && controllers == "" {
+				mountRoot := mounts.root("cgroup2", "")
+				dir, err := resolve(mount, mountRoot, path)
+				if err != nil {
+					return nil, err
+				}
+				for _, c := range groupControllers {
+					g.dirs[c] = dir
+				}
+				g.Root = mountRoot == "/" && filepath.Clean(path) == "/"
+			}
+			continue
+		}
+		// On hybrid hosts the "0::" line is the unified hierarchy, which
+		// only systemd uses; the controllers are all on v1
+		for _, c := range strings.Split(controllers, ",") {
+			if !slices.Contains(groupControllers, c) {
+				continue
+			}
+			for _, root := range []string{filepath.Join(mount, controllers), filepath.Join(mount, c)} {
+				if isDir(root) {
+					dir, err := resolve(root, mounts.root("cgroup", c), path)
+					if err != nil {
+						return nil, err
+					}
+					g.dirs[c] = dir
+					break
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(g.dirs) == 0 {
+		return nil, fmt.Errorf("%s: no cgroup found under %s", procCgroup, mount)
+	}
+	return g, nil
+}
+
+// OpenPath returns the cgroup at path (as listed in /proc/<pid>/cgroup)
+// under the cgroupfs mounted at mount, e.g. a systemd unit's cgroup. On v1
+// only the controllers that have a directory for path are available.
+func OpenPath(mount, path string) (*Group, error) {
+	g := &Group{Version: 1, dirs: map[string]string{}}
+	if _, err := os.Stat(filepath.Join(mount, "cgroup.controllers")); err == nil {
+		g.Version = 2
+	}
+	for _, c := range groupControllers {
+		root := mount
+		if g.Version == 1 {
+			root = filepath.Join(mount, c)
+		}
+		if dir := filepath.Join(root, path); isDir(dir) {
+			g.dirs[c] = dir
+		}
+	}
+	if len(g.dirs) == 0 {
+		return nil, fmt.Errorf("%s: no such cgroup under %s: %w", path, mount, os.ErrNotExist)
+	}
+	return g, nil
+}
+
+// resolve finds the directory of path, as listed in /proc/<pid>/cgroup,
+// under dir, where the hierarchy is mounted from mountRoot
+func resolve(dir, mountRoot, path string) (string, error) {
+	if mountRoot == "/" {
+		joined := filepath.Join(dir, path)
+		if !isDir(joined) {
+			return "", fmt.Errorf("cgroup %s is not under %s: %w", path, dir, os.ErrNotExist)
+		}
+		return joined, nil
+	}
+	// Only part of the hierarchy is mounted, usually the container's own
+	// cgroup. Paths below it map into the mount; anything else resolves
+	// to the mount, the closest cgroup the process can see.
+	if rel, err := filepath.Rel(mountRoot, path); err == nil && rel != ".." && !strings.HasPrefix(rel, "../") {
+		if joined := filepath.Join(dir, rel); isDir(joined) {
+			return joined, nil
+		}
+	}
+	return dir, nil
+}
+
+// cgroupMount is a cgroupfs entry in /proc/<pid>/mountinfo
+type cgroupMount struct {
+	fsType  string
+	root    string
+	options []string
+}
+
+type cgroupMounts []cgroupMount
+
+// readMounts reads the cgroup and cgroup2 mounts from a mountinfo file
+func readMounts(path string) (cgroupMounts, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var mounts cgroupMounts
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// ID parent major:minor root mount-point options [optional...] -
+		// fstype source super-options
+		pre, post, ok := strings.Cut(scanner.Text(), " - ")
+		if !ok {
+			continue
+		}
+		fields, rest := strings.Fields(pre), strings.Fields(post)
+		if len(fields) < 5 || len(rest) < 3 || (rest[0] != "cgroup" && rest[0] != "cgroup2") {
+			continue
+		}
+		mounts = append(mounts, cgroupMount{
+			fsType:  rest[0],
+			root:    unescapeMount(fields[3]),
+			options: strings.Split(rest[2], ","),
+		})
+	}
+	return mounts, scanner.Err()
+}
+
+// root is the part of the hierarchy mounted for a v1 controller, or for v2
+// when controller is empty. It is "/", the whole hierarchy, when there is
+// no such mount.
+func (m cgroupMounts) root(fsType, controller string) string {
+	for _, mount := range m {
+		if mount.fsType == fsType && (controller == "" || slices.Contains(mount.options, controller)) {
+			return mount.root
+		}
+	}
+	return "/"
+}
+
+// unescapeMount undoes the octal escapes mountinfo uses for spaces, tabs,
+// newlines and backslashes in paths
+var unescapeMount = strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`).Replace
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func (g *Group) file(controller, name string) (string, error) {
+	dir, ok := g.dirs[controller]
+	if !ok {
+		return "", fmt.Errorf("the %s cgroup controller is not available", controller)
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// Memory reads the current usage and limit. The working set leaves out
+// inactive page cache, which the kernel reclaims before hitting the limit.
+// It returns ErrRoot for the v2 root cgroup.
+func (g *Group) Memory() (Memory, error) {
+	var m Memory
+	var err error
+	var stat map[string]uint64
+	if g.Root {
+		return m, ErrRoot
+	}
+	if g.Version == 2 {
+		if m.UsageBytes, err = g.readUint("memory", "memory.current"); err != nil {
+			return m, err
+		}
+		if m.LimitBytes, err = g.readLimit("memory", "memory.max"); err != nil {
+			return m, err
+		}
+		stat, err = g.readStat("memory", "memory.stat")
+		m.InactiveFile = stat["inactive_file"]
+	} else {
+		if m.UsageBytes, err = g.readUint("memory", "memory.usage_in_bytes"); err != nil {
+			return m, err
+		}
+		if m.LimitBytes, err = g.readLimit("memory", "memory.limit_in_bytes"); err != nil {
+			return m, err
+		}
+		stat, err = g.readStat("memory", "memory.stat")
+		m.InactiveFile = stat["total_inactive_file"]
+	}
+	if err != nil {
+		return m, err
+	}
+	m.WorkingSetBytes = m.UsageBytes
+	if m.InactiveFile < m.UsageBytes {
+		m.WorkingSetBytes -= m.InactiveFile
+	}
+	return m, nil
+}
+
+// CPU reads cumulative usage, the CFS quota and throttling counters
+func (g *Group) CPU() (CPU, error) {
+	var c CPU
+	if g.Version == 2 {
+		stat, err := g.readStat("cpu", "cpu.stat")
+		if err != nil {
+			return c, err
+		}
+		c.UsageSeconds = float64(stat["usage_usec"]) / 1e6
+		c.Periods = stat["nr_periods"]
+		c.ThrottledPeriods = stat["nr_throttled"]
+		c.ThrottledSeconds = float64(stat["throttled_usec"]) / 1e6
+		if g.Root {
+			// The root cgroup cannot be limited, so has no cpu.max
+			return c, nil
+		}
+		// cpu.max is "<quota|max> <period>"
+		fields, err := g.readFields("cpu", "cpu.max")
+		if err != nil {
+			return c, err
+		}
+		if len(fields) == 2 && fields[0] != "max" {
+			c.QuotaCores = quota(fields[0], fields[1])
+		}
+		return c, nil
+	}
+
+	usage, err := g.readUint("cpuacct", "cpuacct.usage")
+	if err != nil {
+		return c, err
+	}
+	c.UsageSeconds = float64(usage) / 1e9
+	stat, err := g.readStat("cpu", "cpu.stat")
+	if err != nil {
+		return c, err
+	}
+	c.Periods = stat["nr_periods"]
+	c.ThrottledPeriods = stat["nr_throttled"]
+	c.ThrottledSeconds = float64(stat["throttled_time"]) / 1e9
+	q, err := g.readFields("cpu", "cpu.cfs_quota_us")
+	if err != nil {
+		return c, err
+	}
+	p, err := g.readFields("cpu", "cpu.cfs_period_us")
+	if err != nil {
+		return c, err
+	}
+	if len(q) == 1 && len(p) == 1 && q[0] != "-1" {
+		c.QuotaCores = quota(q[0], p[0])
+	}
+	return c, nil
+}
+
+func quota(quota, period string) float64 {
+	q, err1 := strconv.ParseFloat(quota, 64)
+	p, err2 := strconv.ParseFloat(period, 64)
+	if err1 != nil || err2 != nil || q <= 0 || p <= 0 {
+		return 0
+	}
+	return q / p
+}
+
+// Pids reads the number of tasks and the pids.max limit. It returns
+// ErrRoot for the v2 root cgroup.
+func (g *Group) Pids() (Pids, error) {
+	var p Pids
+	var err error
+	if g.Root {
+		return p, ErrRoot
+	}
+	if p.Current, err = g.readUint("pids", "pids.current"); err != nil {
+		return p, err
+	}
+	p.Limit, err = g.readLimit("pids", "pids.max")
+	return p, err
+}
+
+// IO reads the bytes read and written by the cgroup. On v1 this is the
+// throttle accounting, which unlike blkio.io_service_bytes also counts
+// devices without the CFQ scheduler.
+func (g *Group) IO() (IO, error) {
+	var io IO
+	if g.Version == 2 {
+		// One "<major>:<minor> rbytes=N wbytes=N ..." line per device
+		fields, err := g.readFields("blkio", "io.stat")
+		if err != nil {
+			return io, err
+		}
+		for _, f := range fields {
+			key, value, ok := strings.Cut(f, "=")
+			if !ok {
+				continue
+			}
+			v, _ := strconv.ParseUint(value, 10, 64)
+			switch key {
+			case "rbytes":
+				io.ReadBytes += v
+			case "wbytes":
+				io.WriteBytes += v
+			}
+		}
+		return io, nil
+	}
+
+	// "<major>:<minor> <Read|Write|...> N" lines, then a "Total N" line
+	path, err := g.file("blkio", "blkio.throttle.io_service_bytes")
+	if err != nil {
+		return io, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return io, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		v, _ := strconv.ParseUint(fields[2], 10, 64)
+		switch fields[1] {
+		case "Read":
+			io.ReadBytes += v
+		case "Write":
+			io.WriteBytes += v
+		}
+	}
+	return io, nil
+}
+
+// Procs lists the processes in the cgroup
+func (g *Group) Procs() ([]int32, error) {
+	controller := "pids"
+	if _, ok := g.dirs[controller]; !ok {
+		controller = "memory"
+	}
+	fields, err := g.readFields(controller, "cgroup.procs")
+	if err != nil {
+		return nil, err
+	}
+	pids := make([]int32, 0, len(fields))
+	for _, f := range fields {
+		pid, err := strconv.ParseInt(f, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("cgroup.procs: %w", err)
+		}
+		pids = append(pids, int32(pid))
+	}
+	return pids, nil
+}
+
+func (g *Group) readFields(controller, name string) ([]string, error) {
+	path, err := g.file(controller, name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(data)), nil
+}
+
+func (g *Group) readUint(controller, name string) (uint64, error) {
+	fields, err := g.readFields(controller, name)
+	if err != nil {
+		return 0, err
+	}
+	if len(fields) != 1 {
+		return 0, fmt.Errorf("%s: expected one value", name)
+	}
+	v, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	return v, nil
+}
+
+// readLimit reads a limit file, returning 0 for "max" (v2) or the v1
+// "unlimited" value
+func (g *Group) readLimit(controller, name string) (uint64, error) {
+	fields, err := g.readFields(controller, name)
+	if err != nil {
+		return 0, err
+	}
+	if len(fields) != 1 {
+		return 0, fmt.Errorf("%s: expected one value", name)
+	}
+	if fields[0] == "max" {
+		return 0, nil
+	}
+	v, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	if v >= v1Unlimited {
+		return 0, nil
+	}
+	return v, nil
+}
+
+// readStat parses a flat "key value" file such as memory.stat or cpu.stat
+func (g *Group) readStat(controller, name string) (map[string]uint64, error) {
+	path, err := g.file(controller, name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	stat := map[string]uint64{}
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		if v, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64); err == nil {
+			stat[key] = v
+		}
+	}
+	return stat, nil
+}
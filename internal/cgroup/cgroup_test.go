@@ -0,0 +1,161 @@
+// internal/cgroup/cgroup_test.go
+package cgroup
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+)
+
+func open(t *testing.T, tree, self string) *Group {
+	t.Helper()
+	dir := filepath.Join("testdata", tree)
+	g, err := Open(filepath.Join(dir, self), filepath.Join(dir, "mountinfo"), filepath.Join(dir, "sys"))
+	if err != nil {
+		t.Fatalf("Open(%s/%s): %v", tree, self, err)
+	}
+	return g
+}
+
+func TestOpenVersion(t *testing.T) {
+	tests := []struct {
+		tree, self string
+		version    int
+		root       bool
+	}{
+		{"v2", "self", 2, false},
+		{"v2", "root", 2, true},
+		{"v1", "self", 1, false},
+		{"hybrid", "self", 1, false},
+		{"container", "self", 1, false},
+	}
+	for _, tt := range tests {
+		g := open(t, tt.tree, tt.self)
+		if g.Version != tt.version || g.Root != tt.root {
+			t.Errorf("%s/%s: version %d root %v, want %d %v", tt.tree, tt.self, g.Version, g.Root, tt.version, tt.root)
+		}
+	}
+}
+
+func TestOpenMissingPath(t *testing.T) {
+	// The listed cgroup is not in a mount of the whole hierarchy; falling
+	// back to the mount root would report the host as the cgroup
+	tests := []struct{ tree, self, mountInfo string }{
+		{"v2", "missing", "mountinfo"},
+		{"v1", "missing", "mountinfo"},
+		// Without mountinfo the container's mounts look like the host's
+		{"container", "self", "none"},
+	}
+	for _, tt := range tests {
+		dir := filepath.Join("testdata", tt.tree)
+		_, err := Open(filepath.Join(dir, tt.self), filepath.Join(dir, tt.mountInfo), filepath.Join(dir, "sys"))
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%s/%s: got %v, want fs.ErrNotExist", tt.tree, tt.self, err)
+		}
+	}
+}
+
+func TestOpenContainerMount(t *testing.T) {
+	// A v1 container without a cgroup namespace lists its cgroup as
+	// /docker/<id>, which mountinfo shows is mounted at the controllers'
+	// roots. A cgroup outside the mount resolves to the mount itself.
+	for _, self := range []string{"self", "moved"} {
+		g := open(t, "container", self)
+		for _, c := range []string{"memory", "cpu", "cpuacct", "pids"} {
+			want := filepath.Join("testdata", "container", "sys", c)
+			if c == "cpu" || c == "cpuacct" {
+				want = filepath.Join("testdata", "container", "sys", "cpu,cpuacct")
+			}
+			if g.dirs[c] != want {
+				t.Errorf("%s: %s at %s, want %s", self, c, g.dirs[c], want)
+			}
+		}
+	}
+}
+
+func TestMemory(t *testing.T) {
+	tests := []struct {
+		tree, self string
+		want       Memory
+	}{
+		// memory.max is "max"
+		{"v2", "self", Memory{UsageBytes: 314572800, InactiveFile: 52428800, LimitBytes: 0, WorkingSetBytes: 262144000}},
+		{"v2", "limited", Memory{UsageBytes: 268435456, LimitBytes: 536870912, WorkingSetBytes: 268435456}},
+		// memory.limit_in_bytes is the page-aligned maximum int64
+		{"v1", "self", Memory{UsageBytes: 524288000, InactiveFile: 104857600, LimitBytes: 0, WorkingSetBytes: 419430400}},
+		{"hybrid", "self", Memory{UsageBytes: 1048576000, InactiveFile: 48576000, LimitBytes: 2147483648, WorkingSetBytes: 1000000000}},
+		{"container", "self", Memory{UsageBytes: 262144000, InactiveFile: 41943040, LimitBytes: 536870912, WorkingSetBytes: 220200960}},
+	}
+	for _, tt := range tests {
+		got, err := open(t, tt.tree, tt.self).Memory()
+		if err != nil {
+			t.Errorf("%s/%s: %v", tt.tree, tt.self, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s/%s: got %+v, want %+v", tt.tree, tt.self, got, tt.want)
+		}
+	}
+}
+
+func TestCPU(t *testing.T) {
+	tests := []struct {
+		tree, self string
+		want       CPU
+	}{
+		// cpu.max is "150000 100000"
+		{"v2", "self", CPU{UsageSeconds: 12.5, QuotaCores: 1.5, Periods: 400, ThrottledPeriods: 25, ThrottledSeconds: 1.5}},
+		// cpu.max is "max 100000"
+		{"v2", "limited", CPU{UsageSeconds: 2}},
+		// The root cgroup has no cpu.max
+		{"v2", "root", CPU{UsageSeconds: 987.654321}},
+		// cpu.cfs_quota_us is -1
+		{"v1", "self", CPU{UsageSeconds: 4.5, Periods: 1000, ThrottledPeriods: 40, ThrottledSeconds: 2}},
+		{"hybrid", "self", CPU{UsageSeconds: 60, QuotaCores: 0.5, Periods: 200, ThrottledPeriods: 10, ThrottledSeconds: 0.5}},
+		{"container", "self", CPU{UsageSeconds: 3, QuotaCores: 2, Periods: 300, ThrottledPeriods: 6, ThrottledSeconds: 0.12}},
+	}
+	for _, tt := range tests {
+		got, err := open(t, tt.tree, tt.self).CPU()
+		if err != nil {
+			t.Errorf("%s/%s: %v", tt.tree, tt.self, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s/%s: got %+v, want %+v", tt.tree, tt.self, got, tt.want)
+		}
+	}
+}
+
+func TestPids(t *testing.T) {
+	tests := []struct {
+		tree, self string
+		want       Pids
+	}{
+		{"v2", "self", Pids{Current: 12}},
+		{"v2", "limited", Pids{Current: 3, Limit: 64}},
+		{"v1", "self", Pids{Current: 7}},
+		{"container", "self", Pids{Current: 4, Limit: 100}},
+	}
+	for _, tt := range tests {
+		got, err := open(t, tt.tree, tt.self).Pids()
+		if err != nil {
+			t.Errorf("%s/%s: %v", tt.tree, tt.self, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s/%s: got %+v, want %+v", tt.tree, tt.self, got, tt.want)
+		}
+	}
+}
+
+func TestRootHasNoAccounting(t *testing.T) {
+	// Without memory.current the root must not read as zero usage
+	g := open(t, "v2", "root")
+	if m, err := g.Memory(); !errors.Is(err, ErrRoot) {
+		t.Errorf("Memory: got %+v, %v, want ErrRoot", m, err)
+	}
+	if p, err := g.Pids(); !errors.Is(err, ErrRoot) {
+		t.Errorf("Pids: got %+v, %v, want ErrRoot", p, err)
+	}
+}
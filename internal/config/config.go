@@ -0,0 +1,305 @@
+// internal/config/config.go
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds every setting that can come from a config file. Flags
+// override it, and VIGIL_* environment variables override the file.
+type Config struct {
+	Scope      string      `yaml:"scope"`
+	Output     Output      `yaml:"output"`
+	Thresholds Thresholds  `yaml:"thresholds"`
+	Serve      Serve       `yaml:"serve"`
+	Auth       Auth        `yaml:"auth"`
+	History    History     `yaml:"history"`
+	Sampler    Sampler     `yaml:"sampler"`
+	Alerts     []AlertRule `yaml:"alerts"`
+	Exporters  []Exporter  `yaml:"exporters"`
+	Status     Status      `yaml:"status"`
+	Watch      Watch       `yaml:"watch"`
+	Containers Containers  `yaml:"containers"`
+	IO         IO          `yaml:"io"`
+	Forecast   Forecast    `yaml:"forecast"`
+}
+
+// Scopes are the values of Config.Scope: report the whole host, or the
+// cgroup (e.g. the container) vigil runs in
+var Scopes = []string{"host", "cgroup"}
+
+// Output sets the defaults of --format, --template and --quiet
+type Output struct {
+	Format   string `yaml:"format"`
+	Template string `yaml:"template"`
+	Quiet    bool   `yaml:"quiet"`
+}
+
+// Thresholds are the usage percentages at which human and GitHub output
+// flag a resource
+type Thresholds struct {
+	Warning  float64 `yaml:"warning"`
+	Critical float64 `yaml:"critical"`
+}
+
+// Serve configures the `vigil serve` HTTP server. Listen is host:port or
+// unix:/path/to/socket. TLS is enabled when both TLSCert and TLSKey are set.
+type Serve struct {
+	Listen          string   `yaml:"listen"`
+	TLSCert         string   `yaml:"tls_cert"`
+	TLSKey          string   `yaml:"tls_key"`
+	ReadTimeout     Duration `yaml:"read_timeout"`
+	WriteTimeout    Duration `yaml:"write_timeout"`
+	IdleTimeout     Duration `yaml:"idle_timeout"`
+	ShutdownTimeout Duration `yaml:"shutdown_timeout"`
+	DashboardDir    string   `yaml:"dashboard_dir"`
+	StreamInterval  Duration `yaml:"stream_interval"`
+}
+
+// Auth protects `vigil serve`. It is enabled as soon as any tokens, users
+// or a client CA are configured. Roles are "read" (metrics, history,
+// network, dashboard) and "admin" (also processes and system info).
+type Auth struct {
+	Tokens            []AuthToken  `yaml:"tokens"`
+	Users             []AuthUser   `yaml:"users"`
+	ClientCA          string       `yaml:"client_ca"`
+	ClientRoles       []ClientRole `yaml:"client_roles"`
+	DefaultClientRole string       `yaml:"default_client_role"`
+	Anonymous         string       `yaml:"anonymous"`
+	AuditLog          string       `yaml:"audit_log"`
+}
+
+// Enabled reports whether any authentication method is configured
+func (a Auth) Enabled() bool {
+	return len(a.Tokens) > 0 || len(a.Users) > 0 || a.ClientCA != ""
+}
+
+// AuthToken is a static bearer token
+type AuthToken struct {
+	Name  string `yaml:"name"`
+	Token string `yaml:"token"`
+	Role  string `yaml:"role"`
+}
+
+// AuthUser is an HTTP basic auth user with a bcrypt password hash
+type AuthUser struct {
+	Name         string `yaml:"name"`
+	PasswordHash string `yaml:"password_hash"`
+	Role         string `yaml:"role"`
+}
+
+// ClientRole maps the common name of a client certificate to a role
+type ClientRole struct {
+	CommonName string `yaml:"common_name"`
+	Role       string `yaml:"role"`
+}
+
+// History controls how often `vigil serve` records metrics and how many
+// points it keeps. When File is set, history is loaded from it at startup
+// and written back on shutdown.
+type History struct {
+	Limit    int      `yaml:"limit"`
+	Interval Duration `yaml:"interval"`
+	File     string   `yaml:"file"`
+}
+
+// Sampler sets how often `vigil serve` collects each metrics source in
+// the background. Expensive sources can be collected less often.
+type Sampler struct {
+	CPU        Duration `yaml:"cpu"`
+	Memory     Duration `yaml:"memory"`
+	Disk       Duration `yaml:"disk"`
+	Network    Duration `yaml:"network"`
+	Host       Duration `yaml:"host"`
+	Processes  Duration `yaml:"processes"`
+	Containers Duration `yaml:"containers"`
+	Units      Duration `yaml:"units"`
+	Sensors    Duration `yaml:"sensors"`
+	IO         Duration `yaml:"io"`
+}
+
+// AlertRule raises an alert when Metric is above the threshold: a
+// percentage for cpu, memory, swap and disk, degrees Celsius for the
+// hottest temperature, and the number of throttling flags for throttled.
+// Battery rules use Below instead, a charge percentage, and so do
+// days_until_full rules, the days until the root filesystem is predicted
+// to fill up. When several rules match, the one with the most extreme
+// threshold wins.
+type AlertRule struct {
+	Metric string  `yaml:"metric"`
+	Level  string  `yaml:"level"`
+	Above  float64 `yaml:"above,omitempty"`
+	Below  float64 `yaml:"below,omitempty"`
+}
+
+// Exporter sends every history point somewhere else. The only type today
+// is "ndjson", which appends to a file.
+type Exporter struct {
+	Type string `yaml:"type"`
+	Path string `yaml:"path"`
+}
+
+type Status struct {
+	Top      int      `yaml:"top"`
+	Interval Duration `yaml:"interval"`
+}
+
+type Watch struct {
+	Interval Duration `yaml:"interval"`
+}
+
+// Containers sets the Docker or Podman socket; empty finds it from
+// $DOCKER_HOST, $CONTAINER_HOST or the usual paths
+type Containers struct {
+	Socket string `yaml:"socket"`
+}
+
+// IO lists devices `vigil io` and /api/v1/io leave out unless --all (or
+// ?all=true) is given, as path.Match globs
+type IO struct {
+	Exclude []string `yaml:"exclude"`
+}
+
+// Forecast sets how much history the disk forecast fits its trend to.
+// `vigil serve` keeps readings for it apart from the history; `vigil disk
+// --forecast` reads them from an ndjson exporter or from History.File,
+// which only holds History.Limit points.
+type Forecast struct {
+	Window Duration `yaml:"window"`
+}
+
+// Duration is a time.Duration written as "5s" in YAML
+type Duration time.Duration
+
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return time.Duration(d).String(), nil
+}
+
+func (d *Duration) UnmarshalYAML(n *yaml.Node) error {
+	v, err := time.ParseDuration(n.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", n.Line, err)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Default returns the settings used when no config file exists
+func Default() Config {
+	return Config{
+		Scope:      "host",
+		Output:     Output{Format: "human"},
+		Thresholds: Thresholds{Warning: 80, Critical: 95},
+		Serve: Serve{
+			Listen:          "127.0.0.1:8080",
+			ReadTimeout:     Duration(10 * time.Second),
+			WriteTimeout:    Duration(30 * time.Second),
+			IdleTimeout:     Duration(60 * time.Second),
+			ShutdownTimeout: Duration(10 * time.Second),
+			StreamInterval:  Duration(time.Second),
+		},
+		Auth: Auth{
+			Tokens:            []AuthToken{},
+			Users:             []AuthUser{},
+			ClientRoles:       []ClientRole{},
+			DefaultClientRole: "read",
+		},
+		History: History{Limit: 1000, Interval: Duration(5 * time.Second)},
+		Sampler: Sampler{
+			CPU:        Duration(time.Second),
+			Memory:     Duration(time.Second),
+			Disk:       Duration(10 * time.Second),
+			Network:    Duration(time.Second),
+			Host:       Duration(time.Minute),
+			Processes:  Duration(10 * time.Second),
+			Containers: Duration(10 * time.Second),
+			Units:      Duration(10 * time.Second),
+			Sensors:    Duration(10 * time.Second),
+			IO:         Duration(5 * time.Second),
+		},
+		Alerts: []AlertRule{
+			{Metric: "cpu", Level: "warning", Above: 75},
+			{Metric: "cpu", Level: "critical", Above: 90},
+			{Metric: "memory", Level: "warning", Above: 80},
+			{Metric: "memory", Level: "critical", Above: 90},
+			{Metric: "disk", Level: "critical", Above: 90},
+			{Metric: "temperature", Level: "warning", Above: 80},
+			{Metric: "temperature", Level: "critical", Above: 90},
+			{Metric: "throttled", Level: "warning"},
+			{Metric: "battery", Level: "critical", Below: 10},
+			{Metric: "days_until_full", Level: "warning", Below: 7},
+			{Metric: "days_until_full", Level: "critical", Below: 1},
+		},
+		Exporters: []Exporter{},
+		Status:    Status{Top: 5, Interval: Duration(500 * time.Millisecond)},
+		Watch:     Watch{Interval: Duration(2 * time.Second)},
+		IO:        IO{Exclude: []string{"loop*", "ram*"}},
+		Forecast:  Forecast{Window: Duration(24 * time.Hour)},
+	}
+}
+
+// Paths lists where Find looks for a config file, in order
+func Paths() []string {
+	var paths []string
+	if dir, err := os.UserConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(dir, "vigil", "config.yaml"))
+	}
+	return append(paths, "/etc/vigil/config.yaml")
+}
+
+// Find returns the first config file that exists, or "" when there is none
+func Find() string {
+	for _, p := range Paths() {
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	return ""
+}
+
+// Load reads path over the defaults and applies environment overrides. An
+// empty path skips the file. Unknown keys are errors so typos are caught.
+func Load(path string) (Config, error) {
+	cfg := Default()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return cfg, err
+		}
+		if err := Decode(bytes.NewReader(data), &cfg); err != nil {
+			return cfg, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if err := ApplyEnv(&cfg, os.Environ()); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// Decode reads YAML from r into cfg, keeping values the document omits
+func Decode(r io.Reader, cfg *Config) error {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// Encode writes cfg as YAML
+func Encode(w io.Writer, cfg Config) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(cfg); err != nil {
+		return err
+	}
+	return enc.Close()
+}
@@ -0,0 +1,123 @@
+// internal/config/env.go
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix starts every environment override. The rest of the name is the
+// YAML path in upper case joined with underscores: VIGIL_SERVE_LISTEN,
+// VIGIL_HISTORY_LIMIT. Lists (alerts, exporters) can only be set in a file.
+const EnvPrefix = "VIGIL_"
+
+// EnvNames lists every supported override with its YAML path
+func EnvNames() map[string]string {
+	names := map[string]string{}
+	walk("", reflect.ValueOf(&Config{}).Elem(), func(path string, _ reflect.Value) {
+		names[envName(path)] = path
+	})
+	return names
+}
+
+// UnknownEnv returns the VIGIL_* names in environ that are not overrides,
+// usually misspelt ones, sorted. VIGIL_CONFIG names the file and is known.
+func UnknownEnv(environ []string) []string {
+	names := EnvNames()
+	var unknown []string
+	for _, kv := range environ {
+		k, _, _ := strings.Cut(kv, "=")
+		if _, ok := names[k]; !ok && strings.HasPrefix(k, EnvPrefix) && k != EnvPrefix+"CONFIG" {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// ApplyEnv sets fields from KEY=value pairs such as os.Environ()
+func ApplyEnv(cfg *Config, environ []string) error {
+	values := map[string]string{}
+	for _, kv := range environ {
+		if k, v, ok := strings.Cut(kv, "="); ok && strings.HasPrefix(k, EnvPrefix) {
+			values[k] = v
+		}
+	}
+
+	var err error
+	walk("", reflect.ValueOf(cfg).Elem(), func(path string, field reflect.Value) {
+		name := envName(path)
+		v, ok := values[name]
+		if !ok || err != nil {
+			return
+		}
+		if setErr := set(field, v); setErr != nil {
+			err = fmt.Errorf("%s: %w", name, setErr)
+		}
+	})
+	return err
+}
+
+func envName(path string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+}
+
+// walk calls fn for every scalar field, with its dotted YAML path
+func walk(prefix string, v reflect.Value, fn func(path string, field reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		f := v.Field(i)
+		switch f.Kind() {
+		case reflect.Struct:
+			walk(name, f, fn)
+		case reflect.Slice:
+			continue
+		default:
+			fn(name, f)
+		}
+	}
+}
+
+func set(field reflect.Value, s string) error {
+	if field.Type() == reflect.TypeOf(Duration(0)) {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+// internal/config/validate.go
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/sahil3982/vigil/internal/format"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	alertMetrics  = []string{"cpu", "memory", "swap", "disk", "temperature", "throttled", "battery", "days_until_full"}
+	alertLevels   = []string{"warning", "critical"}
+	exporterTypes = []string{"ndjson"}
+	roles         = []string{"read", "admin"}
+)
+
+// Validate reports every invalid setting at once
+func (c Config) Validate() error {
+	var errs []error
+	add := func(msg string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(msg, args...))
+	}
+
+	if !slices.Contains(Scopes, c.Scope) {
+		add("scope: %q is not one of %v", c.Scope, Scopes)
+	}
+	if c.Output.Format != "" && !slices.Contains(format.Names(), c.Output.Format) {
+		add("output.format: unknown format %q", c.Output.Format)
+	}
+	if c.Output.Format == "template" && c.Output.Template == "" {
+		add("output.template: required by the template format")
+	}
+	if t := c.Thresholds; t.Warning <= 0 || t.Warning > t.Critical || t.Critical > 100 {
+		add("thresholds: need 0 < warning (%g) <= critical (%g) <= 100", t.Warning, t.Critical)
+	}
+	if c.Serve.Listen == "" {
+		add("serve.listen: cannot be empty")
+	}
+	if (c.Serve.TLSCert == "") != (c.Serve.TLSKey == "") {
+		add("serve.tls_cert and serve.tls_key must be set together")
+	}
+	if c.Serve.ReadTimeout < 0 || c.Serve.WriteTimeout < 0 || c.Serve.IdleTimeout < 0 || c.Serve.ShutdownTimeout < 0 {
+		add("serve: timeouts cannot be negative")
+	}
+	a := c.Auth
+	for i, t := range a.Tokens {
+		if t.Name == "" || t.Token == "" {
+			add("auth.tokens[%d]: name and token are required", i)
+		}
+		if !slices.Contains(roles, t.Role) {
+			add("auth.tokens[%d].role: %q is not one of %v", i, t.Role, roles)
+		}
+	}
+	for i, u := range a.Users {
+		if u.Name == "" {
+			add("auth.users[%d].name: cannot be empty", i)
+		}
+		if _, err := bcrypt.Cost([]byte(u.PasswordHash)); err != nil {
+			add("auth.users[%d].password_hash: not a bcrypt hash (see `vigil config hash-password`)", i)
+		}
+		if !slices.Contains(roles, u.Role) {
+			add("auth.users[%d].role: %q is not one of %v", i, u.Role, roles)
+		}
+	}
+	for i, r := range a.ClientRoles {
+		if r.CommonName == "" || !slices.Contains(roles, r.Role) {
+			add("auth.client_roles[%d]: needs a common_name and a role from %v", i, roles)
+		}
+	}
+	if !slices.Contains(roles, a.DefaultClientRole) {
+		add("auth.default_client_role: %q is not one of %v", a.DefaultClientRole, roles)
+	}
+	if a.Anonymous != "" && !slices.Contains(roles, a.Anonymous) {
+		add("auth.anonymous: %q is not one of %v (or empty to deny)", a.Anonymous, roles)
+	}
+
+	if c.Serve.StreamInterval <= 0 {
+		add("serve.stream_interval: must be positive")
+	}
+	if c.History.Limit < 1 {
+		add("history.limit: must be at least 1")
+	}
+	if c.History.Interval <= 0 {
+		add("history.interval: must be positive")
+	}
+	for i, a := range c.Alerts {
+		if !slices.Contains(alertMetrics, a.Metric) {
+			add("alerts[%d].metric: %q is not one of %v", i, a.Metric, alertMetrics)
+		}
+		if !slices.Contains(alertLevels, a.Level) {
+			add("alerts[%d].level: %q is not one of %v", i, a.Level, alertLevels)
+		}
+		switch a.Metric {
+		case "battery":
+			if a.Below <= 0 || a.Below > 100 {
+				add("alerts[%d].below: battery rules need a percentage, not %g", i, a.Below)
+			}
+			if a.Above != 0 {
+				add("alerts[%d].above: battery rules use below", i)
+			}
+		case "days_until_full":
+			if a.Below <= 0 {
+				add("alerts[%d].below: days_until_full rules need a positive number of days", i)
+			}
+			if a.Above != 0 {
+				add("alerts[%d].above: days_until_full rules use below", i)
+			}
+		case "temperature", "throttled":
+			if a.Above < 0 {
+				add("alerts[%d].above: cannot be negative", i)
+			}
+		default:
+			if a.Above < 0 || a.Above > 100 {
+				add("alerts[%d].above: %g is not a percentage", i, a.Above)
+			}
+		}
+		if a.Below != 0 && a.Metric != "battery" && a.Metric != "days_until_full" {
+			add("alerts[%d].below: only battery and days_until_full rules use below", i)
+		}
+	}
+	for i, e := range c.Exporters {
+		if !slices.Contains(exporterTypes, e.Type) {
+			add("exporters[%d].type: %q is not one of %v", i, e.Type, exporterTypes)
+		}
+		if e.Path == "" {
+			add("exporters[%d].path: cannot be empty", i)
+		}
+	}
+	samplerIntervals := []struct {
+		name string
+		d    Duration
+	}{
+		{"cpu", c.Sampler.CPU}, {"memory", c.Sampler.Memory}, {"disk", c.Sampler.Disk},
+		{"network", c.Sampler.Network}, {"host", c.Sampler.Host}, {"processes", c.Sampler.Processes},
+		{"containers", c.Sampler.Containers}, {"units", c.Sampler.Units}, {"sensors", c.Sampler.Sensors},
+		{"io", c.Sampler.IO},
+	}
+	for _, s := range samplerIntervals {
+		if s.d <= 0 {
+			add("sampler.%s: must be positive", s.name)
+		}
+	}
+	if c.Status.Top < 0 {
+		add("status.top: cannot be negative")
+	}
+	if c.Status.Interval <= 0 {
+		add("status.interval: must be positive")
+	}
+	if c.Watch.Interval <= 0 {
+		add("watch.interval: must be positive")
+	}
+	if c.Forecast.Window <= 0 {
+		add("forecast.window: must be positive")
+	}
+	for i, pattern := range c.IO.Exclude {
+		if _, err := path.Match(pattern, ""); err != nil {
+			add("io.exclude[%d]: %q is not a valid pattern", i, pattern)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Warnings reports settings that are valid but probably not what was meant
+func (c Config) Warnings() []string {
+	var warnings []string
+	kept := time.Duration(c.History.Limit) * time.Duration(c.History.Interval)
+	exported := slices.ContainsFunc(c.Exporters, func(e Exporter) bool { return e.Type == "ndjson" })
+	if c.History.File != "" && !exported && time.Duration(c.Forecast.Window) > kept {
+		warnings = append(warnings, fmt.Sprintf(
+			"forecast.window (%s) is longer than history.file holds (history.limit × history.interval = %s); add an ndjson exporter for vigil disk --forecast",
+			time.Duration(c.Forecast.Window), kept))
+	}
+	return warnings
+}
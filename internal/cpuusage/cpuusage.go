@@ -0,0 +1,172 @@
+// internal/cpuusage/cpuusage.go
+package cpuusage
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/sahil3982/vigil/internal/cgroup"
+	"github.com/shirou/gopsutil/v3/cpu"
+)
+
+// firstWindow is how long the first Sample measures when there is no
+// earlier reading to compare with
+const firstWindow = 200 * time.Millisecond
+
+// Usage is the share of CPU time spent busy between two readings. In a
+// cgroup, Percent is relative to the CPU quota (or every CPU without one)
+// and PerCore is empty.
+type Usage struct {
+	Percent float64
+	PerCore []float64
+	Elapsed time.Duration
+	// Cgroup is the cgroup's CPU accounting at the latest reading
+	Cgroup *cgroup.CPU
+}
+
+// counters is one reading of busy and total CPU seconds
+type counters struct {
+	busy, total float64
+}
+
+type reading struct {
+	all    counters
+	cores  []counters
+	cgroup *cgroup.CPU
+}
+
+// Sampler computes CPU usage from counter deltas between calls, so every
+// value covers exactly the time since the previous Sample. It is safe for
+// concurrent use.
+type Sampler struct {
+	read func() (reading, error)
+
+	mu   sync.Mutex
+	prev reading
+	at   time.Time
+	last Usage
+}
+
+// New measures the whole host from cpu.Times
+func New() *Sampler {
+	return &Sampler{read: hostReading}
+}
+
+// NewCgroup measures the CPU time used by a cgroup
+func NewCgroup(g *cgroup.Group) *Sampler {
+	return &Sampler{read: func() (reading, error) { return cgroupReading(g) }}
+}
+
+// Measure returns usage of the host over the next d
+func Measure(d time.Duration) (Usage, error) {
+	return New().Measure(d)
+}
+
+// Measure takes a reading, waits d and returns usage over that time
+func (s *Sampler) Measure(d time.Duration) (Usage, error) {
+	if err := s.prime(); err != nil {
+		return Usage{}, err
+	}
+	time.Sleep(d)
+	return s.Sample()
+}
+
+// Sample returns usage since the previous call. The first call measures
+// over a short window instead.
+func (s *Sampler) Sample() (Usage, error) {
+	s.mu.Lock()
+	primed := !s.at.IsZero()
+	s.mu.Unlock()
+	if !primed {
+		if err := s.prime(); err != nil {
+			return Usage{}, err
+		}
+		time.Sleep(firstWindow)
+	}
+
+	r, err := s.read()
+	if err != nil {
+		return Usage{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	all := delta(s.prev.all, r.all)
+	if all.total <= 0 {
+		// The kernel counters have not moved yet; keep the baseline so the
+		// next call covers a longer window
+		return s.last, nil
+	}
+	u := Usage{Percent: all.percent(), Elapsed: now.Sub(s.at), Cgroup: r.cgroup}
+	if len(r.cores) > 0 && len(r.cores) == len(s.prev.cores) {
+		u.PerCore = make([]float64, len(r.cores))
+		for i := range r.cores {
+			u.PerCore[i] = delta(s.prev.cores[i], r.cores[i]).percent()
+		}
+	}
+	s.prev, s.at, s.last = r, now, u
+	return u, nil
+}
+
+func (s *Sampler) prime() error {
+	r, err := s.read()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prev, s.at = r, time.Now()
+	return nil
+}
+
+func hostReading() (reading, error) {
+	total, err := cpu.Times(false)
+	if err != nil {
+		return reading{}, err
+	}
+	if len(total) == 0 {
+		return reading{}, errors.New("no CPU times reported")
+	}
+	r := reading{all: fromTimes(total[0])}
+	// Per-core times are optional; usage still works without them
+	cores, _ := cpu.Times(true)
+	for _, c := range cores {
+		r.cores = append(r.cores, fromTimes(c))
+	}
+	return r, nil
+}
+
+// cgroupReading counts the cgroup's CPU seconds against the wall-clock
+// time its quota allows, so 100% means the quota is used up
+func cgroupReading(g *cgroup.Group) (reading, error) {
+	c, err := g.CPU()
+	if err != nil {
+		return reading{}, err
+	}
+	capacity := c.QuotaCores
+	if capacity <= 0 {
+		capacity = float64(runtime.NumCPU())
+	}
+	wall := float64(time.Now().UnixNano()) / 1e9
+	return reading{all: counters{busy: c.UsageSeconds, total: wall * capacity}, cgroup: &c}, nil
+}
+
+// fromTimes counts busy and total CPU seconds. Guest time is already
+// counted in user time, so it is left out.
+func fromTimes(t cpu.TimesStat) counters {
+	total := t.User + t.System + t.Nice + t.Idle + t.Iowait + t.Irq + t.Softirq + t.Steal
+	return counters{busy: total - t.Idle - t.Iowait, total: total}
+}
+
+func delta(before, after counters) counters {
+	return counters{busy: after.busy - before.busy, total: after.total - before.total}
+}
+
+func (c counters) percent() float64 {
+	if c.total <= 0 {
+		return 0
+	}
+	return min(max(c.busy/c.total*100, 0), 100)
+}
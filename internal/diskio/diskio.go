@@ -0,0 +1,178 @@
+// internal/diskio/diskio.go
+package diskio
+
+import (
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// firstWindow is how long the first Sample measures when there is no
+// earlier reading to compare with
+const firstWindow = 200 * time.Millisecond
+
+// Device is one block device's activity between two readings. Latencies
+// are the average time per completed request, including time queued.
+type Device struct {
+	Name             string
+	ReadIOPS         float64
+	WriteIOPS        float64
+	ReadBytesPerSec  float64
+	WriteBytesPerSec float64
+	ReadLatencyMs    float64
+	WriteLatencyMs   float64
+	// QueueDepth is the average number of requests in flight (iostat's
+	// aqu-sz); InFlight is the number at the latest reading
+	QueueDepth float64
+	InFlight   uint64
+	// UtilPercent is the share of time the device had requests in flight
+	UtilPercent float64
+	// WeightedIOMs is the time spent on requests, weighted by how many
+	// were in flight, over the interval
+	WeightedIOMs uint64
+}
+
+// Sampler computes per-device rates from counter deltas between calls,
+// like cpuusage.Sampler does for CPU time. It is safe for concurrent use.
+type Sampler struct {
+	read func() (map[string]disk.IOCountersStat, error)
+
+	mu   sync.Mutex
+	prev map[string]disk.IOCountersStat
+	at   time.Time
+}
+
+// New reads every device gopsutil reports: /proc/diskstats on Linux
+func New() *Sampler {
+	return &Sampler{read: func() (map[string]disk.IOCountersStat, error) { return disk.IOCounters() }}
+}
+
+// Measure takes a reading, waits d and returns activity over that time
+func (s *Sampler) Measure(d time.Duration) ([]Device, time.Duration, error) {
+	if err := s.prime(); err != nil {
+		return nil, 0, err
+	}
+	time.Sleep(d)
+	return s.Sample()
+}
+
+// Sample returns activity since the previous call, sorted by device name,
+// and the time it covers. The first call measures over a short window.
+func (s *Sampler) Sample() ([]Device, time.Duration, error) {
+	s.mu.Lock()
+	primed := !s.at.IsZero()
+	s.mu.Unlock()
+	if !primed {
+		if err := s.prime(); err != nil {
+			return nil, 0, err
+		}
+		time.Sleep(firstWindow)
+	}
+
+	counters, err := s.read()
+	if err != nil {
+		return nil, 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	elapsed := now.Sub(s.at)
+	devices := make([]Device, 0, len(counters))
+	for name, c := range counters {
+		// A device that appeared since the last reading has no baseline
+		if p, ok := s.prev[name]; ok {
+			devices = append(devices, rates(name, p, c, elapsed))
+		}
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].Name < devices[j].Name })
+	s.prev, s.at = counters, now
+	return devices, elapsed, nil
+}
+
+func (s *Sampler) prime() error {
+	counters, err := s.read()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prev, s.at = counters, time.Now()
+	return nil
+}
+
+func rates(name string, prev, cur disk.IOCountersStat, elapsed time.Duration) Device {
+	secs := elapsed.Seconds()
+	ms := float64(elapsed) / float64(time.Millisecond)
+	reads := delta(prev.ReadCount, cur.ReadCount)
+	writes := delta(prev.WriteCount, cur.WriteCount)
+	d := Device{
+		Name:         name,
+		InFlight:     cur.IopsInProgress,
+		WeightedIOMs: delta(prev.WeightedIO, cur.WeightedIO),
+	}
+	if secs <= 0 {
+		return d
+	}
+	d.ReadIOPS = float64(reads) / secs
+	d.WriteIOPS = float64(writes) / secs
+	d.ReadBytesPerSec = float64(delta(prev.ReadBytes, cur.ReadBytes)) / secs
+	d.WriteBytesPerSec = float64(delta(prev.WriteBytes, cur.WriteBytes)) / secs
+	if reads > 0 {
+		d.ReadLatencyMs = float64(delta(prev.ReadTime, cur.ReadTime)) / float64(reads)
+	}
+	if writes > 0 {
+		d.WriteLatencyMs = float64(delta(prev.WriteTime, cur.WriteTime)) / float64(writes)
+	}
+	if ms > 0 {
+		d.QueueDepth = float64(d.WeightedIOMs) / ms
+		d.UtilPercent = min(float64(delta(prev.IoTime, cur.IoTime))/ms*100, 100)
+	}
+	return d
+}
+
+// delta is the growth of a counter; a counter that went backwards (wrapped
+// or reset with the device) counts as no growth
+func delta(prev, cur uint64) uint64 {
+	if cur < prev {
+		return 0
+	}
+	return cur - prev
+}
+
+// Filter selects devices by name. Patterns are path.Match globs; a device
+// is kept when it matches an Include pattern (or Include is empty) and no
+// Exclude pattern.
+type Filter struct {
+	Include []string
+	Exclude []string
+}
+
+func (f Filter) Keep(name string) bool {
+	if len(f.Include) > 0 && !matchAny(f.Include, name) {
+		return false
+	}
+	return !matchAny(f.Exclude, name)
+}
+
+// Apply returns the devices f keeps
+func (f Filter) Apply(devices []Device) []Device {
+	kept := []Device{}
+	for _, d := range devices {
+		if f.Keep(d.Name) {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,254 @@
+// internal/du/du.go
+package du
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// Entry is a file, or a directory with everything below it. Allocated is
+// the space on disk; it is less than Apparent for sparse and compressed
+// files and more for small ones, which take up whole blocks.
+type Entry struct {
+	Path           string
+	ApparentBytes  uint64
+	AllocatedBytes uint64
+	// Files is the number of files below a directory
+	Files uint64
+}
+
+// Options control a walk. Exclude holds filepath.Match globs matched
+// against both the name and the full path of every entry.
+type Options struct {
+	// Workers is how many directories are read at once; 0 means one per CPU
+	Workers int
+	// CrossFilesystems descends into other filesystems mounted below the
+	// root, which du -x would skip
+	CrossFilesystems bool
+	Exclude          []string
+	// Top is how many directories and files Result lists
+	Top int
+	// ByApparent ranks by apparent instead of allocated size
+	ByApparent bool
+}
+
+// Result is the total below a root and its largest directories (not
+// counting the root) and files. Files with several hard links are counted
+// once. Unreadable is the number of entries that could not be read; Err
+// is the first such error.
+type Result struct {
+	Total      Entry
+	Dirs       []Entry
+	Files      []Entry
+	Unreadable int
+	Err        error
+}
+
+// Walk measures everything below root. Root itself may be a symbolic
+// link; links below it are counted as links and not followed.
+func Walk(root string, opts Options) (Result, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return Result{}, err
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	w := &walker{
+		opts:  opts,
+		root:  root,
+		dev:   statOf(info).dev,
+		sem:   make(chan struct{}, workers-1),
+		seen:  map[fileID]bool{},
+		dirs:  newTop(opts.Top, opts.ByApparent),
+		files: newTop(opts.Top, opts.ByApparent),
+	}
+	var total Entry
+	if info.IsDir() {
+		total = w.dir(root, info)
+	} else {
+		total = w.file(root, info)
+	}
+	return Result{
+		Total:      total,
+		Dirs:       w.dirs.entries,
+		Files:      w.files.entries,
+		Unreadable: w.unreadable,
+		Err:        w.err,
+	}, nil
+}
+
+// fileID identifies a file across its hard links
+type fileID struct {
+	dev, ino uint64
+}
+
+// fileStat is what the platform knows about a file beyond fs.FileInfo.
+// linked is set for files with more than one hard link.
+type fileStat struct {
+	allocated uint64
+	dev       uint64
+	id        fileID
+	linked    bool
+}
+
+type walker struct {
+	opts Options
+	root string
+	dev  uint64
+	// sem holds a token for every goroutine reading a directory besides
+	// the caller's. When none is free, a directory is read inline.
+	sem chan struct{}
+
+	mu         sync.Mutex
+	seen       map[fileID]bool
+	dirs       *top
+	files      *top
+	unreadable int
+	err        error
+}
+
+// dir returns the total of path and everything below it
+func (w *walker) dir(path string, info fs.FileInfo) Entry {
+	own := statOf(info)
+	total := Entry{Path: path, ApparentBytes: uint64(info.Size()), AllocatedBytes: own.allocated}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		w.fail(err)
+	}
+
+	var subdirs []string
+	var subinfos []fs.FileInfo
+	for _, e := range entries {
+		full := filepath.Join(path, e.Name())
+		if w.excluded(e.Name(), full) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			// Removed while being read
+			if !errors.Is(err, fs.ErrNotExist) {
+				w.fail(err)
+			}
+			continue
+		}
+		if info.IsDir() {
+			if !w.opts.CrossFilesystems && statOf(info).dev != w.dev {
+				continue
+			}
+			subdirs = append(subdirs, full)
+			subinfos = append(subinfos, info)
+			continue
+		}
+		add(&total, w.file(full, info))
+	}
+
+	results := make([]Entry, len(subdirs))
+	var wg sync.WaitGroup
+	for i, sub := range subdirs {
+		select {
+		case w.sem <- struct{}{}:
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-w.sem }()
+				results[i] = w.dir(sub, subinfos[i])
+			}()
+		default:
+			results[i] = w.dir(sub, subinfos[i])
+		}
+	}
+	wg.Wait()
+	for _, r := range results {
+		add(&total, r)
+	}
+
+	if path != w.root {
+		w.mu.Lock()
+		w.dirs.offer(total)
+		w.mu.Unlock()
+	}
+	return total
+}
+
+// file returns the size of one file, or nothing for another link to a
+// file already counted
+func (w *walker) file(path string, info fs.FileInfo) Entry {
+	st := statOf(info)
+	e := Entry{Path: path, ApparentBytes: uint64(info.Size()), AllocatedBytes: st.allocated, Files: 1}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if st.linked {
+		if w.seen[st.id] {
+			return Entry{}
+		}
+		w.seen[st.id] = true
+	}
+	w.files.offer(e)
+	return e
+}
+
+func (w *walker) excluded(name, path string) bool {
+	for _, p := range w.opts.Exclude {
+		if ok, _ := filepath.Match(p, name); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(p, path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *walker) fail(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.unreadable++
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+func add(total *Entry, e Entry) {
+	total.ApparentBytes += e.ApparentBytes
+	total.AllocatedBytes += e.AllocatedBytes
+	total.Files += e.Files
+}
+
+// top keeps the n largest entries offered, largest first
+type top struct {
+	n       int
+	size    func(Entry) uint64
+	entries []Entry
+}
+
+func newTop(n int, byApparent bool) *top {
+	t := &top{n: n, size: func(e Entry) uint64 { return e.AllocatedBytes }}
+	if byApparent {
+		t.size = func(e Entry) uint64 { return e.ApparentBytes }
+	}
+	return t
+}
+
+func (t *top) offer(e Entry) {
+	if t.n <= 0 {
+		return
+	}
+	size := t.size(e)
+	if len(t.entries) == t.n && size <= t.size(t.entries[t.n-1]) {
+		return
+	}
+	i := sort.Search(len(t.entries), func(i int) bool { return t.size(t.entries[i]) < size })
+	t.entries = append(t.entries, Entry{})
+	copy(t.entries[i+1:], t.entries[i:])
+	t.entries[i] = e
+	if len(t.entries) > t.n {
+		t.entries = t.entries[:t.n]
+	}
+}
@@ -0,0 +1,24 @@
+//go:build unix
+
+// internal/du/stat_unix.go
+package du
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// statOf reads the allocated blocks, device and inode of a file. Blocks
+// are 512 bytes whatever the filesystem's block size.
+func statOf(info fs.FileInfo) fileStat {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileStat{allocated: uint64(info.Size())}
+	}
+	return fileStat{
+		allocated: uint64(st.Blocks) * 512,
+		dev:       uint64(st.Dev),
+		id:        fileID{dev: uint64(st.Dev), ino: uint64(st.Ino)},
+		linked:    !info.IsDir() && st.Nlink > 1,
+	}
+}
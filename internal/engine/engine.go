@@ -0,0 +1,195 @@
+// internal/engine/engine.go
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Client talks to the Docker Engine API, or Podman's compatible API, over
+// a unix socket
+type Client struct {
+	Socket string
+	http   *http.Client
+}
+
+// Container is an entry of GET /containers/json
+type Container struct {
+	ID     string            `json:"Id"`
+	Names  []string          `json:"Names"`
+	Image  string            `json:"Image"`
+	Labels map[string]string `json:"Labels"`
+	State  string            `json:"State"`
+	Status string            `json:"Status"`
+}
+
+// Name is the container name without the leading slash
+func (c Container) Name() string {
+	if len(c.Names) == 0 {
+		return c.ID[:min(12, len(c.ID))]
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
+
+// Stats is the subset of GET /containers/{id}/stats that vigil reports
+type Stats struct {
+	CPU    CPUStats `json:"cpu_stats"`
+	PreCPU CPUStats `json:"precpu_stats"`
+	Memory struct {
+		Usage uint64            `json:"usage"`
+		Limit uint64            `json:"limit"`
+		Stats map[string]uint64 `json:"stats"`
+	} `json:"memory_stats"`
+	Networks map[string]struct {
+		RxBytes uint64 `json:"rx_bytes"`
+		TxBytes uint64 `json:"tx_bytes"`
+	} `json:"networks"`
+	Blkio struct {
+		IOServiceBytes []struct {
+			Op    string `json:"op"`
+			Value uint64 `json:"value"`
+		} `json:"io_service_bytes_recursive"`
+	} `json:"blkio_stats"`
+	Pids struct {
+		Current uint64 `json:"current"`
+	} `json:"pids_stats"`
+}
+
+type CPUStats struct {
+	Usage struct {
+		Total  uint64   `json:"total_usage"`
+		PerCPU []uint64 `json:"percpu_usage"`
+	} `json:"cpu_usage"`
+	System     uint64 `json:"system_cpu_usage"`
+	OnlineCPUs int    `json:"online_cpus"`
+}
+
+// DefaultSocket finds the engine socket: $DOCKER_HOST or $CONTAINER_HOST
+// when they name a unix socket, then the usual Docker and Podman paths.
+// It returns "" when none exists.
+func DefaultSocket() string {
+	for _, env := range []string{"DOCKER_HOST", "CONTAINER_HOST"} {
+		if path, ok := strings.CutPrefix(os.Getenv(env), "unix://"); ok {
+			return path
+		}
+	}
+	candidates := []string{"/var/run/docker.sock"}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		candidates = append(candidates, filepath.Join(dir, "podman", "podman.sock"), filepath.Join(dir, "docker.sock"))
+	}
+	candidates = append(candidates, "/run/podman/podman.sock")
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+func New(socket string) *Client {
+	dialer := &net.Dialer{}
+	return &Client{
+		Socket: socket,
+		http: &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, "unix", socket)
+			},
+		}},
+	}
+}
+
+// Containers lists running containers, or every container when all is set
+func (c *Client) Containers(ctx context.Context, all bool) ([]Container, error) {
+	var out []Container
+	err := c.get(ctx, "/containers/json?all="+fmt.Sprint(all), &out)
+	return out, err
+}
+
+// Stats reads one stats sample. The engine fills in precpu_stats from its
+// previous sample, which takes it about a second.
+func (c *Client) Stats(ctx context.Context, id string) (Stats, error) {
+	var out Stats
+	err := c.get(ctx, "/containers/"+url.PathEscape(id)+"/stats?stream=false", &out)
+	return out, err
+}
+
+func (c *Client) get(ctx context.Context, path string, v interface{}) error {
+	// The host is ignored; every request goes to the socket
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://engine"+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Message string `json:"message"`
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(body, &e) != nil || e.Message == "" {
+			e.Message = strings.TrimSpace(string(body))
+		}
+		return fmt.Errorf("%s: %s: %s", path, resp.Status, e.Message)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// CPUPercent is the share of the host's CPUs the container used between
+// the two samples, where 100% is one full CPU, as in `docker stats`
+func (s Stats) CPUPercent() float64 {
+	cpuDelta := float64(s.CPU.Usage.Total) - float64(s.PreCPU.Usage.Total)
+	systemDelta := float64(s.CPU.System) - float64(s.PreCPU.System)
+	cpus := s.CPU.OnlineCPUs
+	if cpus == 0 {
+		cpus = len(s.CPU.Usage.PerCPU)
+	}
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0
+	}
+	return cpuDelta / systemDelta * float64(cpus) * 100
+}
+
+// MemoryUsed leaves out inactive page cache, as `docker stats` does
+func (s Stats) MemoryUsed() uint64 {
+	cache, ok := s.Memory.Stats["inactive_file"] // cgroup v2
+	if !ok {
+		cache = s.Memory.Stats["total_inactive_file"] // cgroup v1
+	}
+	if cache > s.Memory.Usage {
+		return s.Memory.Usage
+	}
+	return s.Memory.Usage - cache
+}
+
+// NetworkBytes sums received and sent bytes over every interface
+func (s Stats) NetworkBytes() (rx, tx uint64) {
+	for _, n := range s.Networks {
+		rx += n.RxBytes
+		tx += n.TxBytes
+	}
+	return rx, tx
+}
+
+// BlockBytes sums bytes read from and written to block devices
+func (s Stats) BlockBytes() (read, write uint64) {
+	for _, e := range s.Blkio.IOServiceBytes {
+		switch strings.ToLower(e.Op) {
+		case "read":
+			read += e.Value
+		case "write":
+			write += e.Value
+		}
+	}
+	return read, write
+}
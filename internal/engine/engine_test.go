@@ -0,0 +1,154 @@
+// internal/engine/engine_test.go
+package engine
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	webID = "3f4e5d6c7b8a9f0e1d2c3b4a5f6e7d8c9b0a1f2e3d4c5b6a7f8e9d0c1b2a3f4e"
+	dbID  = "9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5a4b3c2d1e0f9a8b"
+)
+
+// serve replays the responses recorded in testdata on a unix socket, the
+// way the engine answers them
+func serve(t *testing.T) *Client {
+	t.Helper()
+	socket := filepath.Join(t.TempDir(), "engine.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /containers/json", func(w http.ResponseWriter, r *http.Request) {
+		name := "containers.json"
+		if r.URL.Query().Get("all") == "true" {
+			name = "containers-all.json"
+		}
+		http.ServeFile(w, r, filepath.Join("testdata", name))
+	})
+	mux.HandleFunc("GET /containers/{id}/stats", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("stream") != "false" {
+			t.Errorf("stats requested with stream=%q", r.URL.Query().Get("stream"))
+		}
+		data, err := os.ReadFile(filepath.Join("testdata", "stats", filepath.Base(r.PathValue("id"))+".json"))
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"No such container: ` + r.PathValue("id") + `"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(l)
+	t.Cleanup(func() { srv.Close() })
+	return New(socket)
+}
+
+func TestContainers(t *testing.T) {
+	c := serve(t)
+	tests := []struct {
+		all   bool
+		names []string
+	}{
+		{false, []string{"web", "db"}},
+		// A container without names is shown by its short ID
+		{true, []string{"web", "db", "c0ffee00c0ff"}},
+	}
+	for _, tt := range tests {
+		list, err := c.Containers(context.Background(), tt.all)
+		if err != nil {
+			t.Fatalf("all=%v: %v", tt.all, err)
+		}
+		var names []string
+		for _, ct := range list {
+			names = append(names, ct.Name())
+		}
+		if strings.Join(names, ",") != strings.Join(tt.names, ",") {
+			t.Errorf("all=%v: got %v, want %v", tt.all, names, tt.names)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	c := serve(t)
+	tests := []struct {
+		name              string
+		id                string
+		cpuPercent        float64
+		memoryUsed        uint64
+		memoryLimit       uint64
+		rx, tx            uint64
+		blkRead, blkWrite uint64
+		pids              uint64
+	}{
+		{
+			// cgroup v2: online_cpus is set, 2e8 of 2e9 ns over 4 CPUs
+			name: "web", id: webID, cpuPercent: 40,
+			memoryUsed: 104857600 - 20971520, memoryLimit: 8323276800,
+			rx: 1500, tx: 2250, blkRead: 5120, blkWrite: 8192, pids: 5,
+		},
+		{
+			// cgroup v1: CPUs counted from percpu_usage, 5e8 of 5e9 ns
+			// over 2 CPUs; memory net of total_inactive_file, not cache;
+			// Sync, Async and Total are not counted again
+			name: "db", id: dbID, cpuPercent: 20,
+			memoryUsed: 524288000 - 104857600, memoryLimit: 1073741824,
+			blkRead: 1048576, blkWrite: 2097152, pids: 9,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := c.Stats(context.Background(), tt.id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.CPUPercent(); got < tt.cpuPercent-1e-9 || got > tt.cpuPercent+1e-9 {
+				t.Errorf("CPU: got %v%%, want %v%%", got, tt.cpuPercent)
+			}
+			if got := s.MemoryUsed(); got != tt.memoryUsed {
+				t.Errorf("memory used: got %d, want %d", got, tt.memoryUsed)
+			}
+			if s.Memory.Limit != tt.memoryLimit {
+				t.Errorf("memory limit: got %d, want %d", s.Memory.Limit, tt.memoryLimit)
+			}
+			if rx, tx := s.NetworkBytes(); rx != tt.rx || tx != tt.tx {
+				t.Errorf("network: got %d/%d, want %d/%d", rx, tx, tt.rx, tt.tx)
+			}
+			if r, w := s.BlockBytes(); r != tt.blkRead || w != tt.blkWrite {
+				t.Errorf("block IO: got %d/%d, want %d/%d", r, w, tt.blkRead, tt.blkWrite)
+			}
+			if s.Pids.Current != tt.pids {
+				t.Errorf("pids: got %d, want %d", s.Pids.Current, tt.pids)
+			}
+		})
+	}
+}
+
+func TestStatsEngineError(t *testing.T) {
+	_, err := serve(t).Stats(context.Background(), "gone")
+	if err == nil || !strings.Contains(err.Error(), "404") || !strings.Contains(err.Error(), "No such container: gone") {
+		t.Errorf("got %v, want the engine's 404 message", err)
+	}
+}
+
+func TestMissingSocket(t *testing.T) {
+	// collectHint tells the user to start the engine on fs.ErrNotExist
+	c := New(filepath.Join(t.TempDir(), "docker.sock"))
+	if _, err := c.Containers(context.Background(), false); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Containers: got %v, want fs.ErrNotExist", err)
+	}
+	if _, err := c.Stats(context.Background(), webID); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stats: got %v, want fs.ErrNotExist", err)
+	}
+}
@@ -0,0 +1,84 @@
+// internal/forecast/forecast.go
+package forecast
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// MinPoints is how many readings a trend needs; two points would turn
+// any blip into a forecast
+const MinPoints = 3
+
+// ErrTooFewPoints is returned when the window holds fewer than MinPoints
+// readings, e.g. right after the server started
+var ErrTooFewPoints = errors.New("not enough history for a forecast")
+
+// Point is one reading of how much of a filesystem is used
+type Point struct {
+	Time time.Time
+	Used float64
+}
+
+// Trend is a least-squares line through usage over time
+type Trend struct {
+	Points int
+	// Span is the time between the first and last point used
+	Span time.Duration
+	// BytesPerDay is the slope; negative when usage is shrinking
+	BytesPerDay float64
+}
+
+// Linear fits a line through the points no older than window before the
+// latest one. Points may be in any order.
+func Linear(points []Point, window time.Duration) (Trend, error) {
+	points = Recent(points, window)
+	if len(points) < MinPoints {
+		return Trend{Points: len(points)}, ErrTooFewPoints
+	}
+	t := Trend{Points: len(points), Span: points[len(points)-1].Time.Sub(points[0].Time)}
+	if t.Span <= 0 {
+		return t, ErrTooFewPoints
+	}
+
+	// Days since the first point keep the sums small
+	first := points[0].Time
+	var sumX, sumY float64
+	for _, p := range points {
+		sumX += p.Time.Sub(first).Hours() / 24
+		sumY += p.Used
+	}
+	n := float64(len(points))
+	meanX, meanY := sumX/n, sumY/n
+	var cov, varX float64
+	for _, p := range points {
+		dx := p.Time.Sub(first).Hours()/24 - meanX
+		cov += dx * (p.Used - meanY)
+		varX += dx * dx
+	}
+	t.BytesPerDay = cov / varX
+	return t, nil
+}
+
+// DaysUntilFull is how long, at the trend's rate, used takes to reach
+// capacity. ok is false when usage is not growing.
+func (t Trend) DaysUntilFull(used, capacity float64) (days float64, ok bool) {
+	if t.BytesPerDay <= 0 {
+		return 0, false
+	}
+	return max(capacity-used, 0) / t.BytesPerDay, true
+}
+
+// Recent returns the points no older than window before the latest one,
+// sorted by time. A window of 0 keeps every point.
+func Recent(points []Point, window time.Duration) []Point {
+	sorted := append([]Point(nil), points...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+	if window <= 0 || len(sorted) == 0 {
+		return sorted
+	}
+	since := sorted[len(sorted)-1].Time.Add(-window)
+	i := sort.Search(len(sorted), func(i int) bool { return !sorted[i].Time.Before(since) })
+	return sorted[i:]
+}
@@ -0,0 +1,56 @@
+// internal/format/delimited.go
+package format
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// delimited writes a header line followed by one line per row. Columns are
+// the union of all row keys, so rows missing a field get an empty cell.
+func delimited(sep rune) funcFormatter {
+	return func(w io.Writer, v interface{}) error {
+		records := rows(v)
+
+		var header []string
+		seen := map[string]bool{}
+		for _, r := range records {
+			for _, f := range r {
+				if !seen[f.Key] {
+					seen[f.Key] = true
+					header = append(header, f.Key)
+				}
+			}
+		}
+
+		cw := csv.NewWriter(w)
+		cw.Comma = sep
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+		for _, r := range records {
+			values := make(map[string]string, len(r))
+			for _, f := range r {
+				values[f.Key] = f.Value
+			}
+			line := make([]string, len(header))
+			for i, key := range header {
+				line[i] = values[key]
+			}
+			if err := cw.Write(line); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+}
+
+func init() {
+	Register("csv", func(opts Options) (Formatter, error) {
+		return delimited(','), nil
+	})
+	Register("tsv", func(opts Options) (Formatter, error) {
+		return delimited('\t'), nil
+	})
+}
@@ -0,0 +1,164 @@
+// internal/format/fields.go
+package format
+
+import (
+	"io"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// funcFormatter adapts a function that renders any stat into a Formatter,
+// for formats that treat every stat type the same way
+type funcFormatter func(w io.Writer, v interface{}) error
+
+func (f funcFormatter) CPU(w io.Writer, stat CPUStat) error         { return f(w, stat) }
+func (f funcFormatter) Mem(w io.Writer, stat MemStat) error         { return f(w, stat) }
+func (f funcFormatter) Disk(w io.Writer, stat DiskStat) error       { return f(w, stat) }
+func (f funcFormatter) Exec(w io.Writer, stat ExecStat) error       { return f(w, stat) }
+func (f funcFormatter) Bench(w io.Writer, stat BenchStat) error     { return f(w, stat) }
+func (f funcFormatter) Compare(w io.Writer, stat CompareStat) error { return f(w, stat) }
+func (f funcFormatter) Snapshot(w io.Writer, stat Snapshot) error   { return f(w, stat) }
+func (f funcFormatter) Write(w io.Writer, v interface{}) error      { return f(w, v) }
+
+// duRow is a `vigil du` entry with a column saying which list it is from
+type duRow struct {
+	Kind string `json:"kind"`
+	DuEntry
+}
+
+type field struct {
+	Key   string
+	Value string
+}
+
+// rows splits a stat into flat records: one per run for benchmarks, one
+// per command for comparisons, one per container, unit, device or du
+// entry, and a single record otherwise
+func rows(v interface{}) [][]field {
+	var items []interface{}
+	switch s := v.(type) {
+	case BenchStat:
+		for _, r := range s.Results {
+			items = append(items, r)
+		}
+	case CompareStat:
+		for _, c := range s.Commands {
+			// Per-run results would add a column per run and field
+			c.Results = nil
+			items = append(items, c)
+		}
+	case ContainerList:
+		for _, c := range s.Containers {
+			items = append(items, c)
+		}
+	case UnitList:
+		for _, u := range s.Units {
+			items = append(items, u)
+		}
+	case DiskIOStat:
+		for _, d := range s.Devices {
+			items = append(items, d)
+		}
+	case DuStat:
+		for _, d := range s.Dirs {
+			items = append(items, duRow{"dir", d})
+		}
+		for _, f := range s.TopFiles {
+			items = append(items, duRow{"file", f})
+		}
+	default:
+		items = []interface{}{v}
+	}
+
+	out := make([][]field, len(items))
+	for i, item := range items {
+		flatten("", reflect.ValueOf(item), &out[i])
+	}
+	return out
+}
+
+// flatten walks a struct using its JSON names, joining nested names with
+// dots (io.read_bytes) and indexing slices (disks.0.mount) and maps
+// (labels.app). Nil pointers are skipped, and so are omitempty fields that
+// are empty, so the columns match the JSON output.
+func flatten(prefix string, v reflect.Value, out *[]field) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	if t, ok := v.Interface().(time.Time); ok {
+		*out = append(*out, field{prefix, t.Format(time.RFC3339Nano)})
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, k := range keys {
+			flatten(prefix+"."+k.String(), v.MapIndex(k), out)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			flatten(prefix+"."+strconv.Itoa(i), v.Index(i), out)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if isEmpty(v.Field(i)) && slices.Contains(strings.Split(opts, ","), "omitempty") {
+				continue
+			}
+			if f.Anonymous && name == "" {
+				flatten(prefix, v.Field(i), out)
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			if prefix != "" {
+				name = prefix + "." + name
+			}
+			flatten(name, v.Field(i), out)
+		}
+	case reflect.Float32, reflect.Float64:
+		*out = append(*out, field{prefix, strconv.FormatFloat(v.Float(), 'f', -1, 64)})
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		*out = append(*out, field{prefix, strconv.FormatInt(v.Int(), 10)})
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		*out = append(*out, field{prefix, strconv.FormatUint(v.Uint(), 10)})
+	case reflect.Bool:
+		*out = append(*out, field{prefix, strconv.FormatBool(v.Bool())})
+	case reflect.String:
+		*out = append(*out, field{prefix, v.String()})
+	}
+}
+
+// isEmpty reports whether encoding/json would leave out an omitempty field
+// holding v
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
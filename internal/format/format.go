@@ -1,7 +1,12 @@
 // internal/format/format.go
 package format
 
-import "io"
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
 
 // Formatter defines how data is rendered
 type Formatter interface {
@@ -9,6 +14,111 @@ type Formatter interface {
 	Mem(w io.Writer, stat MemStat) error
 	Disk(w io.Writer, stat DiskStat) error
 	Exec(w io.Writer, stat ExecStat) error
+	Bench(w io.Writer, stat BenchStat) error
+	Compare(w io.Writer, stat CompareStat) error
+	Snapshot(w io.Writer, stat Snapshot) error
+}
+
+// Writer is implemented by formats that render every stat the same way,
+// such as json and the flat formats
+type Writer interface {
+	Write(w io.Writer, v interface{}) error
+}
+
+// Write renders a stat that has no method on Formatter, such as a
+// ContainerList. A formatter that renders the type its own way has a
+// method named after it (Containers, Units, Sensors, DiskIO, Du); other
+// formatters fall back to their Write method, or to JSON without one.
+func Write(f Formatter, w io.Writer, v interface{}) error {
+	switch s := v.(type) {
+	case ContainerList:
+		if r, ok := f.(interface {
+			Containers(io.Writer, ContainerList) error
+		}); ok {
+			return r.Containers(w, s)
+		}
+	case UnitList:
+		if r, ok := f.(interface {
+			Units(io.Writer, UnitList) error
+		}); ok {
+			return r.Units(w, s)
+		}
+	case SensorsStat:
+		if r, ok := f.(interface {
+			Sensors(io.Writer, SensorsStat) error
+		}); ok {
+			return r.Sensors(w, s)
+		}
+	case DiskIOStat:
+		if r, ok := f.(interface {
+			DiskIO(io.Writer, DiskIOStat) error
+		}); ok {
+			return r.DiskIO(w, s)
+		}
+	case DuStat:
+		if r, ok := f.(interface{ Du(io.Writer, DuStat) error }); ok {
+			return r.Du(w, s)
+		}
+	}
+	if r, ok := f.(Writer); ok {
+		return r.Write(w, v)
+	}
+	return (&JSONFormatter{}).Write(w, v)
+}
+
+// Options are the output settings shared by every format. Zero thresholds
+// fall back to DefaultWarning and DefaultCritical.
+type Options struct {
+	Quiet    bool
+	Template string
+	Warning  float64
+	Critical float64
+}
+
+// Usage percentages above which resources are flagged by default
+const (
+	DefaultWarning  = 80
+	DefaultCritical = 95
+)
+
+func orDefault(v, def float64) float64 {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
+
+// Factory builds a formatter from the shared options
+type Factory func(opts Options) (Formatter, error)
+
+var registry = map[string]Factory{}
+
+// Register makes a formatter available under name. It is meant to be
+// called from init functions.
+func Register(name string, factory Factory) {
+	if _, dup := registry[name]; dup {
+		panic("format: duplicate formatter " + name)
+	}
+	registry[name] = factory
+}
+
+// Names lists the registered formats in alphabetical order
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Named returns the formatter registered under name
+func Named(name string, opts Options) (Formatter, error) {
+	factory, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return factory(opts)
 }
 
 // New returns a formatter based on flags
@@ -17,4 +127,4 @@ func New(jsonFlag, quietFlag bool) Formatter {
 		return &JSONFormatter{}
 	}
 	return &HumanFormatter{Quiet: quietFlag}
-}
\ No newline at end of file
+}
@@ -0,0 +1,384 @@
+// internal/format/github.go
+package format
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// GitHubFormatter emits GitHub Actions workflow commands (::warning,
+// ::error) on w and appends a markdown table to the job summary file. When
+// SummaryPath is empty the markdown is written to w instead. Usage above
+// Warning percent is annotated.
+type GitHubFormatter struct {
+	SummaryPath string
+	Warning     float64
+}
+
+// NewGitHubFormatter uses the summary file GitHub Actions provides in
+// $GITHUB_STEP_SUMMARY
+func NewGitHubFormatter() *GitHubFormatter {
+	return &GitHubFormatter{SummaryPath: os.Getenv("GITHUB_STEP_SUMMARY")}
+}
+
+// escapeData escapes a workflow command message
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a workflow command property such as title
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+func (g *GitHubFormatter) annotate(w io.Writer, level, title, msg string) error {
+	_, err := fmt.Fprintf(w, "::%s title=%s::%s\n", level, escapeProperty(title), escapeData(msg))
+	return err
+}
+
+func (g *GitHubFormatter) summary(w io.Writer, md string) error {
+	if g.SummaryPath == "" {
+		_, err := io.WriteString(w, md)
+		return err
+	}
+	f, err := os.OpenFile(g.SummaryPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(f, md); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// mdEscape keeps command lines from breaking table cells
+func mdEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+func (g *GitHubFormatter) percent(w io.Writer, name, detail string, percent float64) error {
+	if percent > orDefault(g.Warning, DefaultWarning) {
+		if err := g.annotate(w, "warning", "vigil "+strings.ToLower(name), fmt.Sprintf("%s usage high: %.1f%%", name, percent)); err != nil {
+			return err
+		}
+	}
+	md := "| Metric | Used | Details |\n|---|---|---|\n" +
+		fmt.Sprintf("| %s | %.1f%% | %s |\n\n", name, percent, mdEscape(detail))
+	return g.summary(w, md)
+}
+
+func (g *GitHubFormatter) CPU(w io.Writer, stat CPUStat) error {
+	return g.percent(w, "CPU", fmt.Sprintf("%d cores", stat.Cores), stat.Percent)
+}
+
+func (g *GitHubFormatter) Mem(w io.Writer, stat MemStat) error {
+	return g.percent(w, "Memory", fmt.Sprintf("%.1f / %.1f GB", gb(stat.UsedBytes), gb(stat.TotalBytes)), stat.UsedPercent)
+}
+
+func (g *GitHubFormatter) Disk(w io.Writer, stat DiskStat) error {
+	detail := fmt.Sprintf("%s: %.1f / %.1f GB", stat.Path, gb(stat.UsedBytes), gb(stat.TotalBytes))
+	if f := stat.Forecast; f != nil && f.DaysUntilFull != nil {
+		detail += fmt.Sprintf(", full in %.1f days", *f.DaysUntilFull)
+	}
+	return g.percent(w, "Disk", detail, stat.UsedPercent)
+}
+
+func gb(bytes uint64) float64 {
+	return float64(bytes) / (1024 * 1024 * 1024)
+}
+
+// execAnnotations reports failures and tripped thresholds of one run
+func (g *GitHubFormatter) execAnnotations(w io.Writer, stat ExecStat) error {
+	if stat.ExitCode != 0 {
+		msg := fmt.Sprintf("%s exited with code %d", stat.Command, stat.ExitCode)
+		if stat.Signal != "" {
+			msg += " (" + stat.Signal + ")"
+		}
+		if err := g.annotate(w, "error", "vigil exec", msg); err != nil {
+			return err
+		}
+	}
+	if b := stat.LimitBreach; b != nil {
+		msg := fmt.Sprintf("%s stopped: %s limit exceeded (%.1f > %.1f)", stat.Command, b.Limit, b.Value, b.Threshold)
+		if err := g.annotate(w, "warning", "vigil limit", msg); err != nil {
+			return err
+		}
+	}
+	return g.budgetAnnotations(w, stat.Budget)
+}
+
+func (g *GitHubFormatter) budgetAnnotations(w io.Writer, b *BudgetCheck) error {
+	if b == nil {
+		return nil
+	}
+	for _, m := range b.Metrics {
+		if !m.Regressed {
+			continue
+		}
+		msg := fmt.Sprintf("%s regressed %+.1f%% against %s (%.3f → %.3f, limit %.3f)",
+			m.Name, m.ChangePercent, b.Baseline, m.Baseline, m.Current, m.Limit)
+		if err := g.annotate(w, "warning", "vigil budget", msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func budgetMarkdown(b *BudgetCheck) string {
+	if b == nil || len(b.Metrics) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**Budget vs `%s`** (tolerance %.1f%%)\n\n", b.Baseline, b.TolerancePercent)
+	sb.WriteString("| Metric | Baseline | Current | Change | Limit | |\n|---|---|---|---|---|---|\n")
+	for _, m := range b.Metrics {
+		mark := "✅"
+		if m.Regressed {
+			mark = "❌"
+		}
+		fmt.Fprintf(&sb, "| %s | %.3f ± %.3f | %.3f | %+.1f%% | %.3f | %s |\n",
+			m.Name, m.Baseline, m.BaselineStdDev, m.Current, m.ChangePercent, m.Limit, mark)
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+func (g *GitHubFormatter) Exec(w io.Writer, stat ExecStat) error {
+	if err := g.execAnnotations(w, stat); err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### `%s`\n\n", mdEscape(stat.Command))
+	sb.WriteString("| Metric | Value |\n|---|---|\n")
+	fmt.Fprintf(&sb, "| Exit code | %d |\n", stat.ExitCode)
+	fmt.Fprintf(&sb, "| Time | %.2fs |\n", stat.ElapsedSeconds)
+	fmt.Fprintf(&sb, "| CPU time | %.2fs (avg %.0f%%) |\n", stat.CPUSeconds, stat.CPUAvgPercent)
+	fmt.Fprintf(&sb, "| Peak RAM | %.1f MB |\n", stat.RAMPeakMB)
+	if io := stat.IO; io != nil {
+		fmt.Fprintf(&sb, "| IO read / write | %.1f / %.1f MB |\n", mb(io.ReadBytes), mb(io.WriteBytes))
+	}
+	if b := stat.LimitBreach; b != nil {
+		fmt.Fprintf(&sb, "| Limit exceeded | %s (%.1f > %.1f) |\n", b.Limit, b.Value, b.Threshold)
+	}
+	sb.WriteString("\n")
+	sb.WriteString(budgetMarkdown(stat.Budget))
+	return g.summary(w, sb.String())
+}
+
+func summaryRow(name, unit string, s Summary) string {
+	return fmt.Sprintf("| %s | %.3f%s | %.3f%s | %.3f%s | %.3f%s | %.3f%s |\n",
+		name, s.Mean, unit, s.Median, unit, s.StdDev, unit, s.Min, unit, s.Max, unit)
+}
+
+func (g *GitHubFormatter) Bench(w io.Writer, stat BenchStat) error {
+	if err := g.budgetAnnotations(w, stat.Budget); err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### `%s` — %d runs (%d warmup)\n\n", mdEscape(stat.Command), stat.Runs, stat.Warmup)
+	sb.WriteString("| Metric | Mean | Median | Stddev | Min | Max |\n|---|---|---|---|---|---|\n")
+	sb.WriteString(summaryRow("Time", "s", stat.ElapsedSeconds))
+	sb.WriteString(summaryRow("CPU time", "s", stat.CPUSeconds))
+	sb.WriteString(summaryRow("Peak RAM", " MB", stat.RAMPeakMB))
+	sb.WriteString("\n")
+	if c := stat.Comparison; c != nil {
+		fmt.Fprintf(&sb, "**Compared with `%s`**\n\n", c.Against)
+		sb.WriteString(changeMarkdown(c))
+	}
+	sb.WriteString(budgetMarkdown(stat.Budget))
+	return g.summary(w, sb.String())
+}
+
+func changeMarkdown(c *BenchComparison) string {
+	var sb strings.Builder
+	sb.WriteString("| Metric | Before | After | Ratio | p-value |\n|---|---|---|---|---|\n")
+	row := func(name string, ch BenchChange) {
+		p := "n/a"
+		if ch.PValue != nil {
+			p = fmt.Sprintf("%.3f", *ch.PValue)
+			if ch.Significant {
+				p += " ✱"
+			}
+		}
+		fmt.Fprintf(&sb, "| %s | %.3f | %.3f | %.2fx | %s |\n", name, ch.Baseline, ch.Current, ch.Ratio, p)
+	}
+	row("Time (s)", c.ElapsedSeconds)
+	row("CPU time (s)", c.CPUSeconds)
+	row("Peak RAM (MB)", c.RAMPeakMB)
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+func (g *GitHubFormatter) Compare(w io.Writer, stat CompareStat) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### Comparison — %d runs each (%d warmup)\n\n", stat.Runs, stat.Warmup)
+	sb.WriteString("| | Command | Time | CPU time | Peak RAM | Time ratio |\n|---|---|---|---|---|---|\n")
+	for i, b := range stat.Commands {
+		ratio := "1.00x"
+		if i > 0 && i-1 < len(stat.Comparisons) {
+			ratio = fmt.Sprintf("%.2fx", stat.Comparisons[i-1].ElapsedSeconds.Ratio)
+		}
+		fmt.Fprintf(&sb, "| %c | `%s` | %.3fs ± %.3fs | %.3fs | %.1f MB | %s |\n", 'A'+i, mdEscape(b.Command),
+			b.ElapsedSeconds.Mean, b.ElapsedSeconds.StdDev, b.CPUSeconds.Mean, b.RAMPeakMB.Mean, ratio)
+	}
+	sb.WriteString("\n")
+	return g.summary(w, sb.String())
+}
+
+func (g *GitHubFormatter) Snapshot(w io.Writer, stat Snapshot) error {
+	type row struct {
+		name, detail string
+		percent      float64
+	}
+	rows := []row{
+		{"CPU", fmt.Sprintf("%d cores", stat.CPU.Cores), stat.CPU.Percent},
+		{"Memory", fmt.Sprintf("%.1f / %.1f GB", gb(stat.Mem.UsedBytes), gb(stat.Mem.TotalBytes)), stat.Mem.UsedPercent},
+	}
+	if stat.Swap.TotalBytes > 0 {
+		rows = append(rows, row{"Swap", fmt.Sprintf("%.1f / %.1f GB", gb(stat.Swap.UsedBytes), gb(stat.Swap.TotalBytes)), stat.Swap.UsedPercent})
+	}
+	for _, d := range stat.Disks {
+		rows = append(rows, row{"Disk " + d.Path, fmt.Sprintf("%.1f / %.1f GB", gb(d.UsedBytes), gb(d.TotalBytes)), d.UsedPercent})
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### `%s`\n\n", mdEscape(stat.Hostname))
+	sb.WriteString("| Metric | Used | Details |\n|---|---|---|\n")
+	for _, r := range rows {
+		if r.percent > orDefault(g.Warning, DefaultWarning) {
+			if err := g.annotate(w, "warning", "vigil status", fmt.Sprintf("%s usage high: %.1f%%", r.name, r.percent)); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(&sb, "| %s | %.1f%% | %s |\n", mdEscape(r.name), r.percent, mdEscape(r.detail))
+	}
+	sb.WriteString("\n")
+	return g.summary(w, sb.String())
+}
+
+func (g *GitHubFormatter) Containers(w io.Writer, stat ContainerList) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### Containers (%d)\n\n", len(stat.Containers))
+	sb.WriteString("| Name | Image | CPU | RAM | Net rx / tx | Block r / w |\n|---|---|---|---|---|---|\n")
+	for _, c := range stat.Containers {
+		if c.CPUPercent > orDefault(g.Warning, DefaultWarning) {
+			if err := g.annotate(w, "warning", "vigil containers", fmt.Sprintf("%s CPU usage high: %.1f%%", c.Name, c.CPUPercent)); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(&sb, "| %s | `%s` | %.1f%% | %.1f MB | %.1f / %.1f MB | %.1f / %.1f MB |\n",
+			mdEscape(c.Name), mdEscape(c.Image), c.CPUPercent, mb(c.MemUsedBytes),
+			mb(c.NetRxBytes), mb(c.NetTxBytes), mb(c.BlockReadBytes), mb(c.BlockWriteBytes))
+	}
+	sb.WriteString("\n")
+	return g.summary(w, sb.String())
+}
+
+func (g *GitHubFormatter) Units(w io.Writer, stat UnitList) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### systemd units (%d)\n\n", len(stat.Units))
+	sb.WriteString("| Unit | State | CPU | RAM | Tasks | IO r / w |\n|---|---|---|---|---|---|\n")
+	for _, u := range stat.Units {
+		switch {
+		case u.Active == "failed":
+			if err := g.annotate(w, "error", "vigil units", fmt.Sprintf("%s has failed (%s)", u.Name, u.Sub)); err != nil {
+				return err
+			}
+		case u.CPUPercent > orDefault(g.Warning, DefaultWarning):
+			if err := g.annotate(w, "warning", "vigil units", fmt.Sprintf("%s CPU usage high: %.1f%%", u.Name, u.CPUPercent)); err != nil {
+				return err
+			}
+		}
+		if u.Cgroup == "" {
+			fmt.Fprintf(&sb, "| `%s` | %s/%s | - | - | - | - |\n", mdEscape(u.Name), u.Active, u.Sub)
+			continue
+		}
+		fmt.Fprintf(&sb, "| `%s` | %s/%s | %.1f%% | %.1f MB | %d | %.1f / %.1f MB/s |\n",
+			mdEscape(u.Name), u.Active, u.Sub, u.CPUPercent, mb(u.MemBytes), u.Tasks,
+			mb(uint64(u.IOReadBytesPerSec)), mb(uint64(u.IOWriteBytesPerSec)))
+	}
+	sb.WriteString("\n")
+	return g.summary(w, sb.String())
+}
+
+func (g *GitHubFormatter) Sensors(w io.Writer, stat SensorsStat) error {
+	var sb strings.Builder
+	sb.WriteString("### Sensors\n\n| Sensor | Reading |\n|---|---|\n")
+	for _, t := range stat.Temperatures {
+		switch {
+		case t.CriticalCelsius > 0 && t.Celsius >= t.CriticalCelsius:
+			if err := g.annotate(w, "error", "vigil sensors", fmt.Sprintf("%s at %.1f°C (critical %.1f°C)", t.Sensor, t.Celsius, t.CriticalCelsius)); err != nil {
+				return err
+			}
+		case t.HighCelsius > 0 && t.Celsius >= t.HighCelsius:
+			if err := g.annotate(w, "warning", "vigil sensors", fmt.Sprintf("%s at %.1f°C (high %.1f°C)", t.Sensor, t.Celsius, t.HighCelsius)); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(&sb, "| %s | %.1f°C |\n", mdEscape(t.Sensor), t.Celsius)
+	}
+	for _, f := range stat.Fans {
+		fmt.Fprintf(&sb, "| %s | %d RPM |\n", mdEscape(f.Sensor), f.RPM)
+	}
+	for _, b := range stat.Batteries {
+		fmt.Fprintf(&sb, "| %s | %.0f%% (%s) |\n", mdEscape(b.Name), b.Percent, mdEscape(b.Status))
+	}
+	if len(stat.Throttled) > 0 {
+		if err := g.annotate(w, "warning", "vigil sensors", "Throttled: "+strings.Join(stat.Throttled, ", ")); err != nil {
+			return err
+		}
+		fmt.Fprintf(&sb, "| throttled | %s |\n", mdEscape(strings.Join(stat.Throttled, ", ")))
+	}
+	sb.WriteString("\n")
+	return g.summary(w, sb.String())
+}
+
+func (g *GitHubFormatter) DiskIO(w io.Writer, stat DiskIOStat) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### Disk IO over %.1fs\n\n", stat.IntervalSeconds)
+	sb.WriteString("| Device | IOPS r / w | MB/s r / w | Latency r / w | Queue | Util |\n|---|---|---|---|---|---|\n")
+	for _, d := range stat.Devices {
+		if d.UtilPercent > orDefault(g.Warning, DefaultWarning) {
+			if err := g.annotate(w, "warning", "vigil io", fmt.Sprintf("%s utilization high: %.1f%%", d.Device, d.UtilPercent)); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(&sb, "| %s | %.0f / %.0f | %.1f / %.1f | %.1f / %.1f ms | %.2f | %.1f%% |\n",
+			mdEscape(d.Device), d.ReadIOPS, d.WriteIOPS, mb(uint64(d.ReadBytesPerSec)), mb(uint64(d.WriteBytesPerSec)),
+			d.ReadLatencyMs, d.WriteLatencyMs, d.QueueDepth, d.UtilPercent)
+	}
+	sb.WriteString("\n")
+	return g.summary(w, sb.String())
+}
+
+func (g *GitHubFormatter) Du(w io.Writer, stat DuStat) error {
+	if d := stat.Disk; d != nil && d.UsedPercent > orDefault(g.Warning, DefaultWarning) {
+		if err := g.annotate(w, "warning", "vigil du", fmt.Sprintf("Disk %s usage high: %.1f%%", d.Path, d.UsedPercent)); err != nil {
+			return err
+		}
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### %s: %s on disk in %d files\n\n", mdEscape(stat.Path), humanSize(stat.AllocatedBytes), stat.Files)
+	sb.WriteString("| Kind | Path | On disk | Apparent |\n|---|---|---|---|\n")
+	for _, d := range stat.Dirs {
+		fmt.Fprintf(&sb, "| dir | %s | %s | %s |\n", mdEscape(d.Path), humanSize(d.AllocatedBytes), humanSize(d.ApparentBytes))
+	}
+	for _, f := range stat.TopFiles {
+		fmt.Fprintf(&sb, "| file | %s | %s | %s |\n", mdEscape(f.Path), humanSize(f.AllocatedBytes), humanSize(f.ApparentBytes))
+	}
+	sb.WriteString("\n")
+	return g.summary(w, sb.String())
+}
+
+func init() {
+	Register("github", func(opts Options) (Formatter, error) {
+		g := NewGitHubFormatter()
+		g.Warning = opts.Warning
+		return g, nil
+	})
+}
@@ -5,27 +5,43 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/fatih/color"
 )
 
 type HumanFormatter struct {
-	Quiet bool
+	Quiet    bool
+	Warning  float64
+	Critical float64
 }
 
 func (h *HumanFormatter) bar(value, max float64) string {
 	perc := value / max
 	width := 10
-	filled := int(perc * float64(width))
+	// perc is NaN for a zero max, and out of range for readings such as
+	// an over-full battery
+	filled := 0
+	switch {
+	case perc >= 1:
+		filled = width
+	case perc > 0:
+		filled = int(perc * float64(width))
+	}
 	empty := width - filled
 	return "[" + strings.Repeat("■", filled) + strings.Repeat("□", empty) + "]"
 }
 
+func mb(bytes uint64) float64 {
+	return float64(bytes) / (1024 * 1024)
+}
+
 func (h *HumanFormatter) statusIcon(percent float64) string {
-	if percent > 95 {
+	if percent > orDefault(h.Critical, DefaultCritical) {
 		return color.RedString("🔥")
 	}
-	if percent > 80 {
+	if percent > orDefault(h.Warning, DefaultWarning) {
 		return color.YellowString("⚠️")
 	}
 	return color.GreenString("")
@@ -38,8 +54,24 @@ func (h *HumanFormatter) CPU(w io.Writer, stat CPUStat) error {
 	}
 	bar := h.bar(stat.Percent, 100)
 	status := h.statusIcon(stat.Percent)
-	_, err := color.New(color.FgCyan).Fprintf(w, "▶ CPU: %s %.1f%% %s\n", bar, stat.Percent, status)
-	return err
+	if _, err := color.New(color.FgCyan).Fprintf(w, "▶ CPU%s: %s %.1f%% %s\n", scopeLabel(stat.Scope), bar, stat.Percent, status); err != nil {
+		return err
+	}
+	if t := stat.Throttling; t != nil {
+		quota := "no quota"
+		if stat.QuotaCores > 0 {
+			quota = fmt.Sprintf("quota %.2g cores", stat.QuotaCores)
+		}
+		if _, err := fmt.Fprintf(w, "  %s, throttled %d of %d periods (%.1fs)\n", quota, t.ThrottledPeriods, t.Periods, t.ThrottledSeconds); err != nil {
+			return err
+		}
+	}
+	for i, p := range stat.PerCore {
+		if _, err := fmt.Fprintf(w, "  core %-3d %s %5.1f%% %s\n", i, h.bar(p, 100), p, h.statusIcon(p)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (h *HumanFormatter) Mem(w io.Writer, stat MemStat) error {
@@ -51,11 +83,19 @@ func (h *HumanFormatter) Mem(w io.Writer, stat MemStat) error {
 	status := h.statusIcon(stat.UsedPercent)
 	totalGB := float64(stat.TotalBytes) / (1024 * 1024 * 1024)
 	usedGB := float64(stat.UsedBytes) / (1024 * 1024 * 1024)
-	_, err := color.New(color.FgGreen).Fprintf(w, "▶ RAM: %s %.1f%% (%.1f/%.1f GB) %s\n",
-		bar, stat.UsedPercent, usedGB, totalGB, status)
+	_, err := color.New(color.FgGreen).Fprintf(w, "▶ RAM%s: %s %.1f%% (%.1f/%.1f GB) %s\n",
+		scopeLabel(stat.Scope), bar, stat.UsedPercent, usedGB, totalGB, status)
 	return err
 }
 
+// scopeLabel marks numbers that are not host-wide, e.g. "CPU (cgroup)"
+func scopeLabel(scope string) string {
+	if scope == "" {
+		return ""
+	}
+	return " (" + scope + ")"
+}
+
 func (h *HumanFormatter) Disk(w io.Writer, stat DiskStat) error {
 	if h.Quiet {
 		_, err := fmt.Fprintf(w, "%.1f", stat.UsedPercent)
@@ -67,9 +107,48 @@ func (h *HumanFormatter) Disk(w io.Writer, stat DiskStat) error {
 	usedGB := float64(stat.UsedBytes) / (1024 * 1024 * 1024)
 	_, err := color.New(color.FgYellow).Fprintf(w, "▶ Disk %s: %s %.1f%% (%.1f/%.1f GB) %s\n",
 		stat.Path, bar, stat.UsedPercent, usedGB, totalGB, status)
+	if err != nil || stat.Forecast == nil {
+		return err
+	}
+	return h.diskForecast(w, *stat.Forecast)
+}
+
+func (h *HumanFormatter) diskForecast(w io.Writer, f DiskForecast) error {
+	if f.SpanSeconds == 0 {
+		_, err := fmt.Fprintf(w, "   Forecast: not enough history (%d points in the last %s)\n", f.Points, shortDuration(f.WindowSeconds))
+		return err
+	}
+	trend := fmt.Sprintf("%+.2f GB/day over %s", f.GrowthBytesPerDay/(1024*1024*1024), shortDuration(f.SpanSeconds))
+	days := f.DaysUntilFull
+	if days == nil {
+		_, err := color.New(color.FgGreen).Fprintf(w, "   Forecast: not filling up (%s)\n", trend)
+		return err
+	}
+	c := color.New(color.FgGreen)
+	switch {
+	case *days < 7:
+		c = color.New(color.FgRed)
+	case *days < 30:
+		c = color.New(color.FgYellow)
+	}
+	when := ""
+	if f.FullAt != nil {
+		when = " (" + f.FullAt.Local().Format("Jan 2 15:04") + ")"
+	}
+	_, err := c.Fprintf(w, "   Forecast: full in %.1f days%s, %s\n", *days, when, trend)
 	return err
 }
 
+// shortDuration rounds to the minute and drops zero units: 23h, not
+// 23h0m0s
+func shortDuration(seconds float64) string {
+	d := time.Duration(seconds * float64(time.Second)).Round(time.Minute)
+	if d == 0 {
+		return "<1m"
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(d.String(), "0s"), "0m")
+}
+
 func (h *HumanFormatter) Exec(w io.Writer, stat ExecStat) error {
 	status := ""
 	if stat.ExitCode != 0 {
@@ -81,8 +160,474 @@ func (h *HumanFormatter) Exec(w io.Writer, stat ExecStat) error {
 	}
 	color.New(color.FgWhite).Fprintf(w, "──────────────────────────────────────\n")
 	color.New(color.FgCyan).Fprintf(w, "▶ Finished in %.2fs %s\n", stat.ElapsedSeconds, status)
-	color.New(color.FgGreen).Fprintf(w, "   CPU: avg %.0f%%\n", stat.CPUAvgPercent)
+	color.New(color.FgGreen).Fprintf(w, "   CPU: avg %.0f%% (%.2fs CPU time)\n", stat.CPUAvgPercent, stat.CPUSeconds)
 	color.New(color.FgGreen).Fprintf(w, "   RAM: peak %.1f MB\n", stat.RAMPeakMB)
-	color.New(color.FgWhite).Fprintf(w, "   Exit code: %d\n", stat.ExitCode)
+	if io := stat.IO; io != nil {
+		color.New(color.FgGreen).Fprintf(w, "   IO:  read %.1f MB (%d syscalls), write %.1f MB (%d syscalls)\n",
+			mb(io.ReadBytes), io.ReadSyscalls, mb(io.WriteBytes), io.WriteSyscalls)
+		if io.NetRxBytes > 0 || io.NetTxBytes > 0 {
+			scope := ""
+			if io.NetScope == "namespace" {
+				scope = " (whole network namespace, not just the command)"
+			}
+			color.New(color.FgGreen).Fprintf(w, "   Net: rx %.1f MB, tx %.1f MB%s\n", mb(io.NetRxBytes), mb(io.NetTxBytes), scope)
+		}
+	}
+	switch {
+	case stat.CoreDumped:
+		color.New(color.FgWhite).Fprintf(w, "   Exit code: %d (%s, core dumped)\n", stat.ExitCode, stat.Signal)
+	case stat.Signal != "":
+		color.New(color.FgWhite).Fprintf(w, "   Exit code: %d (%s)\n", stat.ExitCode, stat.Signal)
+	default:
+		color.New(color.FgWhite).Fprintf(w, "   Exit code: %d\n", stat.ExitCode)
+	}
+	if b := stat.LimitBreach; b != nil {
+		unit := "s"
+		if b.Limit == "rss" {
+			unit = " MB"
+		}
+		color.New(color.FgRed).Fprintf(w, "   Limit exceeded: %s (%.1f%s > %.1f%s)\n",
+			b.Limit, b.Value, unit, b.Threshold, unit)
+	}
+	h.budget(w, stat.Budget)
+	return nil
+}
+
+func (h *HumanFormatter) Bench(w io.Writer, stat BenchStat) error {
+	if h.Quiet {
+		_, err := fmt.Fprintf(w, "%.3f", stat.ElapsedSeconds.Mean)
+		return err
+	}
+	color.New(color.FgWhite).Fprintf(w, "──────────────────────────────────────\n")
+	color.New(color.FgCyan).Fprintf(w, "▶ %d runs of %s (%d warmup)\n", stat.Runs, stat.Command, stat.Warmup)
+	h.summary(w, color.FgGreen, "Time", stat.ElapsedSeconds, "%.3fs")
+	h.summary(w, color.FgGreen, "CPU", stat.CPUSeconds, "%.3fs")
+	h.summary(w, color.FgGreen, "RAM", stat.RAMPeakMB, "%.1f MB")
+	if len(stat.Outliers) > 0 {
+		runs := make([]string, len(stat.Outliers))
+		for i, idx := range stat.Outliers {
+			runs[i] = fmt.Sprint(idx + 1)
+		}
+		color.New(color.FgYellow).Fprintf(w, "   ⚠️ Outliers (run %s) may skew the results\n", strings.Join(runs, ", "))
+	}
+	if c := stat.Comparison; c != nil {
+		color.New(color.FgCyan).Fprintf(w, "▶ Compared with %s\n", c.Against)
+		h.change(w, "Time", c.ElapsedSeconds, "%.3fs")
+		h.change(w, "CPU", c.CPUSeconds, "%.3fs")
+		h.change(w, "RAM", c.RAMPeakMB, "%.1f MB")
+	}
+	h.budget(w, stat.Budget)
+	return nil
+}
+
+func (h *HumanFormatter) summary(w io.Writer, attr color.Attribute, name string, s Summary, valueFmt string) {
+	v := func(f float64) string { return fmt.Sprintf(valueFmt, f) }
+	color.New(attr).Fprintf(w, "   %-5s %s ± %s  (median %s, min %s, max %s)\n",
+		name+":", v(s.Mean), v(s.StdDev), v(s.Median), v(s.Min), v(s.Max))
+}
+
+func (h *HumanFormatter) change(w io.Writer, name string, c BenchChange, valueFmt string) {
+	detail := "not enough runs for significance"
+	if c.PValue != nil {
+		verdict := "not significant"
+		if c.Significant {
+			verdict = "significant"
+		}
+		detail = fmt.Sprintf("p=%.3f, %s", *c.PValue, verdict)
+	}
+	if c.Baseline == 0 {
+		color.New(color.FgWhite).Fprintf(w, "   %-5s "+valueFmt+" → "+valueFmt+" (%s)\n",
+			name+":", c.Baseline, c.Current, detail)
+		return
+	}
+	pct := (c.Ratio - 1) * 100
+	attr := color.FgWhite
+	if c.Significant && pct > 0 {
+		attr = color.FgRed
+	} else if c.Significant && pct < 0 {
+		attr = color.FgGreen
+	}
+	color.New(attr).Fprintf(w, "   %-5s "+valueFmt+" → "+valueFmt+" (%+.1f%%, %s)\n",
+		name+":", c.Baseline, c.Current, pct, detail)
+}
+
+func (h *HumanFormatter) Compare(w io.Writer, stat CompareStat) error {
+	if h.Quiet {
+		// Time ratio of each command relative to the first
+		ratios := make([]string, len(stat.Comparisons))
+		for i, c := range stat.Comparisons {
+			ratios[i] = fmt.Sprintf("%.3f", c.ElapsedSeconds.Ratio)
+		}
+		_, err := fmt.Fprint(w, strings.Join(ratios, " "))
+		return err
+	}
+	color.New(color.FgWhite).Fprintf(w, "──────────────────────────────────────\n")
+	color.New(color.FgCyan).Fprintf(w, "▶ Compared %d commands (%d runs each, %d warmup)\n",
+		len(stat.Commands), stat.Runs, stat.Warmup)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "   \tCommand\tTime\tCPU\tRAM\t")
+	for i, b := range stat.Commands {
+		fmt.Fprintf(tw, "   %c\t%s\t%.3fs ± %.3fs\t%.3fs\t%.1f MB\t\n", 'A'+i, b.Command,
+			b.ElapsedSeconds.Mean, b.ElapsedSeconds.StdDev, b.CPUSeconds.Mean, b.RAMPeakMB.Mean)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for i, c := range stat.Comparisons {
+		color.New(color.FgCyan).Fprintf(w, "▶ %c vs A: time %s, CPU %s, RAM %s\n", 'B'+i,
+			h.ratio(c.ElapsedSeconds), h.ratio(c.CPUSeconds), h.ratio(c.RAMPeakMB))
+	}
 	return nil
 }
+
+func (h *HumanFormatter) Containers(w io.Writer, stat ContainerList) error {
+	if h.Quiet {
+		// One "name cpu mem" line per container
+		lines := make([]string, len(stat.Containers))
+		for i, c := range stat.Containers {
+			lines[i] = fmt.Sprintf("%s %.1f %.1f", c.Name, c.CPUPercent, c.MemPercent)
+		}
+		_, err := fmt.Fprint(w, strings.Join(lines, "\n"))
+		return err
+	}
+	if len(stat.Containers) == 0 {
+		_, err := color.New(color.FgCyan).Fprintf(w, "▶ No containers running (%s)\n", stat.Socket)
+		return err
+	}
+	color.New(color.FgCyan).Fprintf(w, "▶ %d containers (%s)\n", len(stat.Containers), stat.Socket)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "   Name\tImage\tCPU\tRAM\tNet rx/tx\tBlock r/w\tPIDs\t")
+	for _, c := range stat.Containers {
+		mem := fmt.Sprintf("%.1f MB", mb(c.MemUsedBytes))
+		if c.MemLimitBytes > 0 {
+			mem += fmt.Sprintf(" (%.1f%%)", c.MemPercent)
+		}
+		fmt.Fprintf(tw, "   %s\t%s\t%.1f%% %s\t%s\t%.1f / %.1f MB\t%.1f / %.1f MB\t%d\t\n",
+			c.Name, c.Image, c.CPUPercent, h.statusIcon(c.CPUPercent), mem,
+			mb(c.NetRxBytes), mb(c.NetTxBytes), mb(c.BlockReadBytes), mb(c.BlockWriteBytes), c.Pids)
+	}
+	return tw.Flush()
+}
+
+func (h *HumanFormatter) Units(w io.Writer, stat UnitList) error {
+	if h.Quiet {
+		// One "name cpu mem" line per unit
+		lines := make([]string, len(stat.Units))
+		for i, u := range stat.Units {
+			lines[i] = fmt.Sprintf("%s %.1f %d", u.Name, u.CPUPercent, u.MemBytes)
+		}
+		_, err := fmt.Fprint(w, strings.Join(lines, "\n"))
+		return err
+	}
+	if len(stat.Units) == 0 {
+		_, err := color.New(color.FgCyan).Fprintln(w, "▶ No matching units")
+		return err
+	}
+	color.New(color.FgCyan).Fprintf(w, "▶ %d units\n", len(stat.Units))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "   Unit\tState\tCPU\tRAM\tTasks\tIO r/w\t")
+	for _, u := range stat.Units {
+		state := u.Active
+		if u.Sub != "" && u.Sub != u.Active {
+			state += "/" + u.Sub
+		}
+		if u.Active == "failed" {
+			state = color.RedString(state)
+		}
+		if u.Cgroup == "" {
+			// No processes, so nothing was measured
+			fmt.Fprintf(tw, "   %s\t%s\t-\t-\t-\t-\t\n", u.Name, state)
+			continue
+		}
+		mem := fmt.Sprintf("%.1f MB", mb(u.MemBytes))
+		if u.MemLimitBytes > 0 {
+			mem += fmt.Sprintf(" of %.1f MB", mb(u.MemLimitBytes))
+		}
+		fmt.Fprintf(tw, "   %s\t%s\t%.1f%% %s\t%s\t%d\t%.1f / %.1f MB/s\t\n",
+			u.Name, state, u.CPUPercent, h.statusIcon(u.CPUPercent), mem, u.Tasks,
+			mb(uint64(u.IOReadBytesPerSec)), mb(uint64(u.IOWriteBytesPerSec)))
+	}
+	return tw.Flush()
+}
+
+func (h *HumanFormatter) Sensors(w io.Writer, stat SensorsStat) error {
+	if h.Quiet {
+		// The hottest temperature
+		_, err := fmt.Fprintf(w, "%.1f", stat.MaxCelsius)
+		return err
+	}
+	if len(stat.Temperatures) > 0 {
+		color.New(color.FgCyan).Fprintln(w, "▶ Temperatures")
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, t := range stat.Temperatures {
+			limits := ""
+			if t.HighCelsius > 0 {
+				limits = fmt.Sprintf("high %.1f°C", t.HighCelsius)
+			}
+			if t.CriticalCelsius > 0 {
+				limits = strings.TrimPrefix(limits+fmt.Sprintf(", critical %.1f°C", t.CriticalCelsius), ", ")
+			}
+			fmt.Fprintf(tw, "   %s\t%.1f°C %s\t%s\t\n", t.Sensor, t.Celsius, h.tempIcon(t), limits)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	if len(stat.Fans) > 0 {
+		color.New(color.FgCyan).Fprintln(w, "▶ Fans")
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, f := range stat.Fans {
+			fmt.Fprintf(tw, "   %s\t%d RPM\t\n", f.Sensor, f.RPM)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	h.throttling(w, stat)
+	h.batteries(w, stat)
+	return nil
+}
+
+// tempIcon flags a temperature at its throttling or critical threshold
+func (h *HumanFormatter) tempIcon(t TempStat) string {
+	switch {
+	case t.CriticalCelsius > 0 && t.Celsius >= t.CriticalCelsius:
+		return color.RedString("🔥")
+	case t.HighCelsius > 0 && t.Celsius >= t.HighCelsius:
+		return color.YellowString("⚠️")
+	}
+	return ""
+}
+
+func (h *HumanFormatter) throttling(w io.Writer, stat SensorsStat) {
+	if len(stat.Throttled) > 0 {
+		color.New(color.FgRed).Fprintf(w, "▶ Throttled: %s\n", strings.Join(stat.Throttled, ", "))
+	} else if len(stat.ThrottledSinceBoot) > 0 {
+		color.New(color.FgGreen).Fprintln(w, "▶ Throttled: no")
+	}
+	if len(stat.ThrottledSinceBoot) > 0 {
+		color.New(color.FgYellow).Fprintf(w, "  since boot: %s\n", strings.Join(stat.ThrottledSinceBoot, ", "))
+	}
+}
+
+func (h *HumanFormatter) batteries(w io.Writer, stat SensorsStat) {
+	for _, b := range stat.Batteries {
+		// A low battery is the problem, so flag the charge that is gone
+		color.New(color.FgGreen).Fprintf(w, "▶ Battery %s: %s %.0f%% (%s) %s\n",
+			b.Name, h.bar(b.Percent, 100), b.Percent, b.Status, h.statusIcon(100-b.Percent))
+	}
+	if ac := stat.ACOnline; ac != nil {
+		state := "offline"
+		if *ac {
+			state = "online"
+		}
+		color.New(color.FgGreen).Fprintf(w, "▶ AC power: %s\n", state)
+	}
+}
+
+func (h *HumanFormatter) DiskIO(w io.Writer, stat DiskIOStat) error {
+	if h.Quiet {
+		// One "device util" line per device
+		lines := make([]string, len(stat.Devices))
+		for i, d := range stat.Devices {
+			lines[i] = fmt.Sprintf("%s %.1f", d.Device, d.UtilPercent)
+		}
+		_, err := fmt.Fprint(w, strings.Join(lines, "\n"))
+		return err
+	}
+	if len(stat.Devices) == 0 {
+		_, err := color.New(color.FgCyan).Fprintln(w, "▶ No matching devices")
+		return err
+	}
+	color.New(color.FgCyan).Fprintf(w, "▶ Disk IO over %.1fs\n", stat.IntervalSeconds)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "   Device\tIOPS r/w\tMB/s r/w\tLatency r/w\tQueue\tUtil\t")
+	for _, d := range stat.Devices {
+		fmt.Fprintf(tw, "   %s\t%.0f / %.0f\t%.1f / %.1f\t%.1f / %.1f ms\t%.2f\t%.1f%% %s\t\n",
+			d.Device, d.ReadIOPS, d.WriteIOPS, mb(uint64(d.ReadBytesPerSec)), mb(uint64(d.WriteBytesPerSec)),
+			d.ReadLatencyMs, d.WriteLatencyMs, d.QueueDepth, d.UtilPercent, h.statusIcon(d.UtilPercent))
+	}
+	return tw.Flush()
+}
+
+func (h *HumanFormatter) Du(w io.Writer, stat DuStat) error {
+	total := duSize(stat, DuEntry{ApparentBytes: stat.ApparentBytes, AllocatedBytes: stat.AllocatedBytes})
+	if h.Quiet {
+		_, err := fmt.Fprintf(w, "%d", total)
+		return err
+	}
+	if stat.Disk != nil {
+		if err := h.Disk(w, *stat.Disk); err != nil {
+			return err
+		}
+	}
+	color.New(color.FgCyan).Fprintf(w, "▶ %s: %s on disk (%s apparent) in %d files, %.1fs\n",
+		stat.Path, humanSize(stat.AllocatedBytes), humanSize(stat.ApparentBytes), stat.Files, stat.ElapsedSeconds)
+	lists := []struct {
+		title   string
+		entries []DuEntry
+	}{
+		{"Largest directories", stat.Dirs},
+		{"Largest files", stat.TopFiles},
+	}
+	for _, l := range lists {
+		if len(l.entries) == 0 {
+			continue
+		}
+		color.New(color.FgCyan).Fprintf(w, "▶ %s\n", l.title)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "   Share\t\tOn disk\tApparent\tPath\t")
+		for _, e := range l.entries {
+			// Bars show the share of everything below the path
+			share := 0.0
+			if total > 0 {
+				share = float64(duSize(stat, e)) / float64(total) * 100
+			}
+			fmt.Fprintf(tw, "   %s\t%.1f%%\t%s\t%s\t%s\t\n",
+				h.bar(share, 100), share, humanSize(e.AllocatedBytes), humanSize(e.ApparentBytes), e.Path)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// humanSize formats bytes with a binary unit: 512 B, 4.0 KB, 113.8 GB
+func humanSize(bytes uint64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	value, i := float64(bytes)/unit, 0
+	for value >= unit && i < 4 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f %cB", value, "KMGTP"[i])
+}
+
+func (h *HumanFormatter) ratio(c BenchChange) string {
+	if c.Baseline == 0 {
+		return "n/a"
+	}
+	s := fmt.Sprintf("%.2fx", c.Ratio)
+	if c.Significant {
+		return s + " (significant)"
+	}
+	return s
+}
+
+func (h *HumanFormatter) budget(w io.Writer, b *BudgetCheck) {
+	if b == nil {
+		return
+	}
+	if b.Updated {
+		color.New(color.FgGreen).Fprintf(w, "✓ Baseline %s updated\n", b.Baseline)
+		return
+	}
+	color.New(color.FgCyan).Fprintf(w, "▶ Budget vs %s (tolerance %.1f%%)\n", b.Baseline, b.TolerancePercent)
+	for _, m := range b.Metrics {
+		mark, attr := "✓", color.FgGreen
+		if m.Regressed {
+			mark, attr = "✗", color.FgRed
+		}
+		color.New(attr).Fprintf(w, "   %s %-16s %.3f ± %.3f → %.3f (%+.1f%%, limit %.3f)\n",
+			mark, m.Name, m.Baseline, m.BaselineStdDev, m.Current, m.ChangePercent, m.Limit)
+	}
+	if b.Regressed {
+		color.New(color.FgRed).Fprintf(w, "✗ Performance regression against %s\n", b.Baseline)
+	}
+}
+
+func (h *HumanFormatter) Snapshot(w io.Writer, stat Snapshot) error {
+	if h.Quiet {
+		// CPU, RAM and root disk percentages
+		disk := 0.0
+		if len(stat.Disks) > 0 {
+			disk = stat.Disks[0].UsedPercent
+		}
+		_, err := fmt.Fprintf(w, "%.1f %.1f %.1f", stat.CPU.Percent, stat.Mem.UsedPercent, disk)
+		return err
+	}
+	up := time.Duration(stat.UptimeSeconds) * time.Second
+	header := fmt.Sprintf("▶ %s — up %s", stat.Hostname, uptime(up))
+	if l := stat.Load; l != nil {
+		header += fmt.Sprintf(", load %.2f %.2f %.2f", l.Load1, l.Load5, l.Load15)
+	}
+	if p := stat.Pids; p != nil {
+		header += fmt.Sprintf(", %d tasks", p.Current)
+		if p.Limit > 0 {
+			header += fmt.Sprintf(" (max %d)", p.Limit)
+		}
+	}
+	color.New(color.FgWhite).Fprintln(w, header)
+	color.New(color.FgWhite).Fprintf(w, "──────────────────────────────────────\n")
+
+	if err := h.CPU(w, stat.CPU); err != nil {
+		return err
+	}
+	if err := h.Mem(w, stat.Mem); err != nil {
+		return err
+	}
+	if stat.Swap.TotalBytes > 0 {
+		color.New(color.FgGreen).Fprintf(w, "▶ Swap: %s %.1f%% (%.1f/%.1f GB) %s\n", h.bar(stat.Swap.UsedPercent, 100),
+			stat.Swap.UsedPercent, gb(stat.Swap.UsedBytes), gb(stat.Swap.TotalBytes), h.statusIcon(stat.Swap.UsedPercent))
+	}
+	for _, d := range stat.Disks {
+		if err := h.Disk(w, d); err != nil {
+			return err
+		}
+	}
+	for _, n := range stat.Net {
+		color.New(color.FgBlue).Fprintf(w, "▶ Net %s: rx %.1f KB/s, tx %.1f KB/s\n",
+			n.Interface, n.RxBytesPerSec/1024, n.TxBytesPerSec/1024)
+	}
+	if s := stat.Sensors; s != nil {
+		// Only the hottest sensor; `vigil sensors` lists them all
+		var hottest *TempStat
+		for i, t := range s.Temperatures {
+			if hottest == nil || t.Celsius > hottest.Celsius {
+				hottest = &s.Temperatures[i]
+			}
+		}
+		if hottest != nil {
+			color.New(color.FgMagenta).Fprintf(w, "▶ Temp: %.1f°C (%s) %s\n", hottest.Celsius, hottest.Sensor, h.tempIcon(*hottest))
+		}
+		h.throttling(w, *s)
+		h.batteries(w, *s)
+	}
+
+	if len(stat.Processes) == 0 {
+		return nil
+	}
+	color.New(color.FgCyan).Fprintln(w, "▶ Top processes")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "   PID\tCPU\tRAM\tName\t")
+	for _, p := range stat.Processes {
+		fmt.Fprintf(tw, "   %d\t%.1f%%\t%.1f MB\t%s\t\n", p.PID, p.CPUPercent, mb(p.RSSBytes), p.Name)
+	}
+	return tw.Flush()
+}
+
+// uptime formats a duration as days, hours and minutes
+func uptime(d time.Duration) string {
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	mins := int(d.Minutes()) % 60
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh", days, hours)
+	}
+	if hours > 0 {
+		return fmt.Sprintf("%dh %dm", hours, mins)
+	}
+	return fmt.Sprintf("%dm", mins)
+}
+
+func init() {
+	Register("human", func(opts Options) (Formatter, error) {
+		return &HumanFormatter{Quiet: opts.Quiet, Warning: opts.Warning, Critical: opts.Critical}, nil
+	})
+}
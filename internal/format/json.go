@@ -22,4 +22,30 @@ func (j *JSONFormatter) Disk(w io.Writer, stat DiskStat) error {
 
 func (j *JSONFormatter) Exec(w io.Writer, stat ExecStat) error {
 	return json.NewEncoder(w).Encode(stat)
-}
\ No newline at end of file
+}
+func (j *JSONFormatter) Bench(w io.Writer, stat BenchStat) error {
+	return json.NewEncoder(w).Encode(stat)
+}
+
+func (j *JSONFormatter) Compare(w io.Writer, stat CompareStat) error {
+	return json.NewEncoder(w).Encode(stat)
+}
+
+func (j *JSONFormatter) Snapshot(w io.Writer, stat Snapshot) error {
+	return json.NewEncoder(w).Encode(stat)
+}
+
+// Write encodes any stat
+func (j *JSONFormatter) Write(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+func init() {
+	Register("json", func(opts Options) (Formatter, error) {
+		return &JSONFormatter{}, nil
+	})
+	// The JSON encoder already writes one compact document per line
+	Register("ndjson", func(opts Options) (Formatter, error) {
+		return &JSONFormatter{}, nil
+	})
+}
@@ -0,0 +1,368 @@
+// internal/format/junit.go
+package format
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// JUnitFormatter renders results as JUnit XML so CI dashboards can show
+// timings and treat failed commands as failed tests
+type JUnitFormatter struct{}
+
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitCase     `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Type    string `xml:"type,attr"`
+	Message string `xml:"message,attr"`
+}
+
+func (j *JUnitFormatter) write(w io.Writer, suite junitSuite) error {
+	suite.Timestamp = time.Now().UTC().Format("2006-01-02T15:04:05")
+	suite.Tests = len(suite.Cases)
+	for _, c := range suite.Cases {
+		if c.Failure != nil {
+			suite.Failures++
+		}
+	}
+	if suite.Time == "" {
+		suite.Time = "0"
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitSuites{Suites: []junitSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(v float64) string {
+	return fmt.Sprintf("%.3f", v)
+}
+
+func prop(name string, format string, v interface{}) junitProperty {
+	return junitProperty{Name: name, Value: fmt.Sprintf(format, v)}
+}
+
+func (j *JUnitFormatter) percentSuite(name string, percent float64, props ...junitProperty) junitSuite {
+	return junitSuite{
+		Name:       "vigil." + name,
+		Properties: append([]junitProperty{prop("used_percent", "%.1f", percent)}, props...),
+		Cases:      []junitCase{{ClassName: "vigil." + name, Name: name, Time: "0"}},
+	}
+}
+
+func (j *JUnitFormatter) CPU(w io.Writer, stat CPUStat) error {
+	return j.write(w, j.percentSuite("cpu", stat.Percent, prop("cores", "%d", stat.Cores)))
+}
+
+func (j *JUnitFormatter) Mem(w io.Writer, stat MemStat) error {
+	return j.write(w, j.percentSuite("mem", stat.UsedPercent,
+		prop("total_bytes", "%d", stat.TotalBytes),
+		prop("used_bytes", "%d", stat.UsedBytes)))
+}
+
+func (j *JUnitFormatter) Disk(w io.Writer, stat DiskStat) error {
+	props := []junitProperty{
+		prop("mount", "%s", stat.Path),
+		prop("total_bytes", "%d", stat.TotalBytes),
+		prop("used_bytes", "%d", stat.UsedBytes),
+	}
+	if f := stat.Forecast; f != nil {
+		props = append(props, prop("growth_bytes_per_day", "%.0f", f.GrowthBytesPerDay))
+		if f.DaysUntilFull != nil {
+			props = append(props, prop("days_until_full", "%.1f", *f.DaysUntilFull))
+		}
+	}
+	return j.write(w, j.percentSuite("disk", stat.UsedPercent, props...))
+}
+
+// execFailures lists every reason a command run should fail its testcase
+func execFailures(stat ExecStat) []junitFailure {
+	var out []junitFailure
+	if stat.ExitCode != 0 {
+		msg := fmt.Sprintf("exit code %d", stat.ExitCode)
+		if stat.Signal != "" {
+			msg += " (" + stat.Signal + ")"
+		}
+		out = append(out, junitFailure{Type: "exit_code", Message: msg})
+	}
+	if b := stat.LimitBreach; b != nil {
+		out = append(out, junitFailure{Type: "limit", Message: fmt.Sprintf("%s limit exceeded: %.1f > %.1f", b.Limit, b.Value, b.Threshold)})
+	}
+	out = append(out, budgetFailures(stat.Budget)...)
+	return out
+}
+
+// mergeFailures folds several failure reasons into the single <failure>
+// element a JUnit testcase may carry
+func mergeFailures(fs []junitFailure) *junitFailure {
+	if len(fs) == 0 {
+		return nil
+	}
+	merged := fs[0]
+	for _, f := range fs[1:] {
+		merged.Message += "; " + f.Message
+	}
+	return &merged
+}
+
+func budgetFailures(b *BudgetCheck) []junitFailure {
+	if b == nil {
+		return nil
+	}
+	var out []junitFailure
+	for _, m := range b.Metrics {
+		if m.Regressed {
+			out = append(out, junitFailure{Type: "regression", Message: fmt.Sprintf("%s regressed %+.1f%% against %s (limit %.3f, got %.3f)",
+				m.Name, m.ChangePercent, b.Baseline, m.Limit, m.Current)})
+		}
+	}
+	return out
+}
+
+func execProperties(stat ExecStat) []junitProperty {
+	props := []junitProperty{
+		prop("exit_code", "%d", stat.ExitCode),
+		prop("cpu_seconds", "%.3f", stat.CPUSeconds),
+		prop("cpu_avg_percent", "%.1f", stat.CPUAvgPercent),
+		prop("ram_peak_mb", "%.1f", stat.RAMPeakMB),
+	}
+	if io := stat.IO; io != nil {
+		props = append(props,
+			prop("io_read_bytes", "%d", io.ReadBytes),
+			prop("io_write_bytes", "%d", io.WriteBytes))
+	}
+	return props
+}
+
+func (j *JUnitFormatter) Exec(w io.Writer, stat ExecStat) error {
+	return j.write(w, junitSuite{
+		Name:       "vigil.exec",
+		Time:       seconds(stat.ElapsedSeconds),
+		Properties: execProperties(stat),
+		Cases: []junitCase{{
+			ClassName: "vigil.exec",
+			Name:      stat.Command,
+			Time:      seconds(stat.ElapsedSeconds),
+			Failure:   mergeFailures(execFailures(stat)),
+		}},
+	})
+}
+
+func summaryProperties(prefix string, s Summary) []junitProperty {
+	return []junitProperty{
+		prop(prefix+"_mean", "%.3f", s.Mean),
+		prop(prefix+"_median", "%.3f", s.Median),
+		prop(prefix+"_stddev", "%.3f", s.StdDev),
+		prop(prefix+"_min", "%.3f", s.Min),
+		prop(prefix+"_max", "%.3f", s.Max),
+	}
+}
+
+func (j *JUnitFormatter) Bench(w io.Writer, stat BenchStat) error {
+	suite := junitSuite{Name: "vigil.bench"}
+	suite.Properties = append(suite.Properties, summaryProperties("elapsed_seconds", stat.ElapsedSeconds)...)
+	suite.Properties = append(suite.Properties, summaryProperties("cpu_seconds", stat.CPUSeconds)...)
+	suite.Properties = append(suite.Properties, summaryProperties("ram_peak_mb", stat.RAMPeakMB)...)
+
+	total := 0.0
+	for i, r := range stat.Results {
+		total += r.ElapsedSeconds
+		suite.Cases = append(suite.Cases, junitCase{
+			ClassName: "vigil.bench",
+			Name:      fmt.Sprintf("%s [run %d]", stat.Command, i+1),
+			Time:      seconds(r.ElapsedSeconds),
+			Failure:   mergeFailures(execFailures(r)),
+		})
+	}
+	if failures := budgetFailures(stat.Budget); len(failures) > 0 {
+		suite.Cases = append(suite.Cases, junitCase{
+			ClassName: "vigil.bench",
+			Name:      stat.Command + " [budget]",
+			Time:      "0",
+			Failure:   mergeFailures(failures),
+		})
+	}
+	suite.Time = seconds(total)
+	return j.write(w, suite)
+}
+
+func (j *JUnitFormatter) Compare(w io.Writer, stat CompareStat) error {
+	suite := junitSuite{Name: "vigil.compare"}
+	total := 0.0
+	for i, b := range stat.Commands {
+		total += b.ElapsedSeconds.Mean * float64(b.Runs)
+		suite.Cases = append(suite.Cases, junitCase{
+			ClassName: "vigil.compare",
+			Name:      b.Command,
+			Time:      seconds(b.ElapsedSeconds.Mean),
+		})
+		if i > 0 && i-1 < len(stat.Comparisons) {
+			c := stat.Comparisons[i-1]
+			suite.Properties = append(suite.Properties,
+				prop(fmt.Sprintf("ratio_%c_elapsed", 'A'+i), "%.3f", c.ElapsedSeconds.Ratio),
+				prop(fmt.Sprintf("ratio_%c_cpu", 'A'+i), "%.3f", c.CPUSeconds.Ratio),
+				prop(fmt.Sprintf("ratio_%c_ram", 'A'+i), "%.3f", c.RAMPeakMB.Ratio))
+		}
+	}
+	suite.Time = seconds(total)
+	return j.write(w, suite)
+}
+
+// Snapshot reports every resource as a passing testcase; the readings are
+// suite properties
+func (j *JUnitFormatter) Snapshot(w io.Writer, stat Snapshot) error {
+	suite := junitSuite{
+		Name: "vigil.status",
+		Properties: []junitProperty{
+			prop("hostname", "%s", stat.Hostname),
+			prop("uptime_seconds", "%d", stat.UptimeSeconds),
+			prop("cpu_percent", "%.1f", stat.CPU.Percent),
+			prop("mem_used_percent", "%.1f", stat.Mem.UsedPercent),
+			prop("swap_used_percent", "%.1f", stat.Swap.UsedPercent),
+		},
+		Cases: []junitCase{
+			{ClassName: "vigil.status", Name: "cpu", Time: "0"},
+			{ClassName: "vigil.status", Name: "mem", Time: "0"},
+		},
+	}
+	if l := stat.Load; l != nil {
+		suite.Properties = append(suite.Properties, prop("load1", "%.2f", l.Load1))
+	}
+	for _, d := range stat.Disks {
+		suite.Properties = append(suite.Properties, prop("disk_used_percent:"+d.Path, "%.1f", d.UsedPercent))
+		suite.Cases = append(suite.Cases, junitCase{ClassName: "vigil.status", Name: "disk " + d.Path, Time: "0"})
+	}
+	return j.write(w, suite)
+}
+
+// Containers reports every container as a passing testcase with its
+// readings as properties
+func (j *JUnitFormatter) Containers(w io.Writer, stat ContainerList) error {
+	suite := junitSuite{Name: "vigil.containers"}
+	for _, c := range stat.Containers {
+		suite.Properties = append(suite.Properties,
+			prop("cpu_percent:"+c.Name, "%.1f", c.CPUPercent),
+			prop("mem_used_bytes:"+c.Name, "%d", c.MemUsedBytes))
+		suite.Cases = append(suite.Cases, junitCase{ClassName: "vigil.containers", Name: c.Name, Time: "0"})
+	}
+	return j.write(w, suite)
+}
+
+// Units reports every unit as a testcase that fails when the unit has
+// failed, with its readings as properties
+func (j *JUnitFormatter) Units(w io.Writer, stat UnitList) error {
+	suite := junitSuite{Name: "vigil.units"}
+	for _, u := range stat.Units {
+		suite.Properties = append(suite.Properties,
+			prop("cpu_percent:"+u.Name, "%.1f", u.CPUPercent),
+			prop("mem_bytes:"+u.Name, "%d", u.MemBytes))
+		c := junitCase{ClassName: "vigil.units", Name: u.Name, Time: "0"}
+		if u.Active == "failed" {
+			c.Failure = &junitFailure{Type: "failed", Message: fmt.Sprintf("%s is %s/%s", u.Name, u.Active, u.Sub)}
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+	return j.write(w, suite)
+}
+
+// Sensors reports every temperature as a testcase that fails at the
+// sensor's critical threshold, and throttling as one more that fails
+// while the CPU is throttled
+func (j *JUnitFormatter) Sensors(w io.Writer, stat SensorsStat) error {
+	suite := junitSuite{Name: "vigil.sensors"}
+	for _, t := range stat.Temperatures {
+		suite.Properties = append(suite.Properties, prop("celsius:"+t.Sensor, "%.1f", t.Celsius))
+		c := junitCase{ClassName: "vigil.sensors", Name: t.Sensor, Time: "0"}
+		if t.CriticalCelsius > 0 && t.Celsius >= t.CriticalCelsius {
+			c.Failure = &junitFailure{Type: "critical", Message: fmt.Sprintf("%s at %.1f°C (critical %.1f°C)", t.Sensor, t.Celsius, t.CriticalCelsius)}
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+	for _, b := range stat.Batteries {
+		suite.Properties = append(suite.Properties, prop("battery_percent:"+b.Name, "%.0f", b.Percent))
+	}
+	c := junitCase{ClassName: "vigil.sensors", Name: "throttling", Time: "0"}
+	if len(stat.Throttled) > 0 {
+		c.Failure = &junitFailure{Type: "throttled", Message: strings.Join(stat.Throttled, ", ")}
+	}
+	suite.Cases = append(suite.Cases, c)
+	return j.write(w, suite)
+}
+
+// DiskIO reports every device as a passing testcase with its readings as
+// properties
+func (j *JUnitFormatter) DiskIO(w io.Writer, stat DiskIOStat) error {
+	suite := junitSuite{Name: "vigil.io", Time: fmt.Sprintf("%.3f", stat.IntervalSeconds)}
+	for _, d := range stat.Devices {
+		suite.Properties = append(suite.Properties,
+			prop("util_percent:"+d.Device, "%.1f", d.UtilPercent),
+			prop("read_latency_ms:"+d.Device, "%.2f", d.ReadLatencyMs),
+			prop("write_latency_ms:"+d.Device, "%.2f", d.WriteLatencyMs))
+		suite.Cases = append(suite.Cases, junitCase{ClassName: "vigil.io", Name: d.Device, Time: "0"})
+	}
+	return j.write(w, suite)
+}
+
+// Du reports the total and the largest entries as properties of a single
+// passing testcase
+func (j *JUnitFormatter) Du(w io.Writer, stat DuStat) error {
+	suite := junitSuite{
+		Name: "vigil.du",
+		Time: seconds(stat.ElapsedSeconds),
+		Properties: []junitProperty{
+			prop("path", "%s", stat.Path),
+			prop("allocated_bytes", "%d", stat.AllocatedBytes),
+			prop("apparent_bytes", "%d", stat.ApparentBytes),
+			prop("files", "%d", stat.Files),
+		},
+		Cases: []junitCase{{ClassName: "vigil.du", Name: stat.Path, Time: seconds(stat.ElapsedSeconds)}},
+	}
+	for _, d := range stat.Dirs {
+		suite.Properties = append(suite.Properties, prop("dir:"+d.Path, "%d", duSize(stat, d)))
+	}
+	for _, f := range stat.TopFiles {
+		suite.Properties = append(suite.Properties, prop("file:"+f.Path, "%d", duSize(stat, f)))
+	}
+	return j.write(w, suite)
+}
+
+func init() {
+	Register("junit", func(opts Options) (Formatter, error) {
+		return &JUnitFormatter{}, nil
+	})
+}
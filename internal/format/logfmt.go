@@ -0,0 +1,32 @@
+// internal/format/logfmt.go
+package format
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// logfmt writes each row as one line of key=value pairs
+func logfmt(w io.Writer, v interface{}) error {
+	for _, r := range rows(v) {
+		parts := make([]string, len(r))
+		for i, f := range r {
+			value := f.Value
+			if value == "" || strings.ContainsAny(value, " =\"\t\n") {
+				value = strconv.Quote(value)
+			}
+			parts[i] = f.Key + "=" + value
+		}
+		if _, err := io.WriteString(w, strings.Join(parts, " ")+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	Register("logfmt", func(opts Options) (Formatter, error) {
+		return funcFormatter(logfmt), nil
+	})
+}
@@ -0,0 +1,38 @@
+// internal/format/template.go
+package format
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"text/template"
+)
+
+// templateFormat executes a Go template against the stat itself, so field
+// names are the Go ones: {{.UsedPercent}}, {{.IO.ReadBytes}}
+func templateFormat(text string) (funcFormatter, error) {
+	tmpl, err := template.New("format").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return func(w io.Writer, v interface{}) error {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, v); err != nil {
+			return err
+		}
+		if buf.Len() == 0 || buf.Bytes()[buf.Len()-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+		_, err := w.Write(buf.Bytes())
+		return err
+	}, nil
+}
+
+func init() {
+	Register("template", func(opts Options) (Formatter, error) {
+		if opts.Template == "" {
+			return nil, errors.New("the template format needs --template")
+		}
+		return templateFormat(opts.Template)
+	})
+}
@@ -1,31 +1,384 @@
 // internal/format/types.go
 package format
 
+import "time"
+
 type CPUStat struct {
-	Percent float64 `json:"cpu_percent"`
-	Cores   int     `json:"cores,omitempty"`
+	Percent    float64       `json:"cpu_percent"`
+	Cores      int           `json:"cores,omitempty"`
+	PerCore    []float64     `json:"per_core,omitempty"`
+	Scope      string        `json:"scope,omitempty"`
+	QuotaCores float64       `json:"quota_cores,omitempty"`
+	Throttling *ThrottleStat `json:"throttling,omitempty"`
+}
+
+// ThrottleStat is how often a cgroup hit its CPU quota since it started
+type ThrottleStat struct {
+	Periods          uint64  `json:"periods"`
+	ThrottledPeriods uint64  `json:"throttled_periods"`
+	ThrottledSeconds float64 `json:"throttled_seconds"`
 }
 
+// MemStat is host memory, or with Scope "cgroup" the cgroup's working set.
+// TotalBytes is then the smaller of its limit (LimitBytes) and host memory.
 type MemStat struct {
 	TotalBytes     uint64  `json:"total_bytes"`
 	UsedBytes      uint64  `json:"used_bytes"`
 	FreeBytes      uint64  `json:"free_bytes"`
 	UsedPercent    float64 `json:"used_percent"`
 	AvailableBytes uint64  `json:"available_bytes,omitempty"`
+	Scope          string  `json:"scope,omitempty"`
+	LimitBytes     uint64  `json:"limit_bytes,omitempty"`
 }
 
 type DiskStat struct {
-	Path        string  `json:"mount"`
+	Path        string        `json:"mount"`
+	TotalBytes  uint64        `json:"total_bytes"`
+	UsedBytes   uint64        `json:"used_bytes"`
+	FreeBytes   uint64        `json:"free_bytes"`
+	UsedPercent float64       `json:"used_percent"`
+	Forecast    *DiskForecast `json:"forecast,omitempty"`
+}
+
+// DiskForecast is when a filesystem fills up if usage keeps growing at
+// the rate it did over the window. DaysUntilFull is nil when there is too
+// little history or usage is not growing.
+type DiskForecast struct {
+	WindowSeconds     float64    `json:"window_seconds"`
+	Points            int        `json:"points"`
+	SpanSeconds       float64    `json:"span_seconds"`
+	GrowthBytesPerDay float64    `json:"growth_bytes_per_day"`
+	DaysUntilFull     *float64   `json:"days_until_full"`
+	FullAt            *time.Time `json:"full_at,omitempty"`
+}
+
+type ExecStat struct {
+	Command        string       `json:"command"`
+	ExitCode       int          `json:"exit_code"`
+	ElapsedSeconds float64      `json:"elapsed_seconds"`
+	CPUSeconds     float64      `json:"cpu_seconds"`
+	CPUAvgPercent  float64      `json:"cpu_avg_percent"`
+	RAMPeakMB      float64      `json:"ram_peak_mb"`
+	IO             *IOStat      `json:"io,omitempty"`
+	Signal         string       `json:"signal,omitempty"`
+	CoreDumped     bool         `json:"core_dumped,omitempty"`
+	LimitBreach    *LimitBreach `json:"limit_breach,omitempty"`
+	Budget         *BudgetCheck `json:"budget,omitempty"`
+}
+
+// IOStat is the IO done by a command and all of its descendants. Network
+// bytes are counted for the command's whole network namespace (excluding
+// loopback) and are only available on Linux. NetScope is "namespace" when
+// the command shares vigil's namespace, so the bytes include every other
+// process in it, and "command" when the command has one of its own.
+type IOStat struct {
+	ReadBytes     uint64 `json:"read_bytes"`
+	WriteBytes    uint64 `json:"write_bytes"`
+	ReadSyscalls  uint64 `json:"read_syscalls"`
+	WriteSyscalls uint64 `json:"write_syscalls"`
+	NetRxBytes    uint64 `json:"net_rx_bytes,omitempty"`
+	NetTxBytes    uint64 `json:"net_tx_bytes,omitempty"`
+	NetScope      string `json:"net_scope,omitempty"`
+}
+
+// LimitBreach records which resource limit stopped a command. Threshold and
+// Value are in MB for "rss" and seconds for "time" and "cpu_seconds".
+type LimitBreach struct {
+	Limit     string  `json:"limit"`
+	Threshold float64 `json:"threshold"`
+	Value     float64 `json:"value"`
+}
+
+type ExecSample struct {
+	Time           time.Time `json:"time"`
+	ElapsedSeconds float64   `json:"elapsed_seconds"`
+	RSSBytes       uint64    `json:"rss_bytes"`
+	CPUPercent     float64   `json:"cpu_percent"`
+	Threads        int32     `json:"threads"`
+	ReadBytes      uint64    `json:"read_bytes"`
+	WriteBytes     uint64    `json:"write_bytes"`
+}
+
+// Summary describes the distribution of one metric over repeated runs
+type Summary struct {
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	StdDev float64 `json:"stddev"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+}
+
+type BenchStat struct {
+	Command        string           `json:"command"`
+	Runs           int              `json:"runs"`
+	Warmup         int              `json:"warmup"`
+	ElapsedSeconds Summary          `json:"elapsed_seconds"`
+	CPUSeconds     Summary          `json:"cpu_seconds"`
+	RAMPeakMB      Summary          `json:"ram_peak_mb"`
+	Outliers       []int            `json:"outliers,omitempty"`
+	Comparison     *BenchComparison `json:"comparison,omitempty"`
+	Budget         *BudgetCheck     `json:"budget,omitempty"`
+	Results        []ExecStat       `json:"results"`
+}
+
+// BenchComparison relates a benchmark to an earlier one loaded from disk
+type BenchComparison struct {
+	Against        string      `json:"against"`
+	ElapsedSeconds BenchChange `json:"elapsed_seconds"`
+	CPUSeconds     BenchChange `json:"cpu_seconds"`
+	RAMPeakMB      BenchChange `json:"ram_peak_mb"`
+}
+
+// BenchChange compares the means of one metric. Ratio is current/baseline
+// (0 when the baseline is 0); PValue comes from Welch's t-test and is omitted when either side has
+// fewer than two runs.
+type BenchChange struct {
+	Baseline    float64  `json:"baseline"`
+	Current     float64  `json:"current"`
+	Ratio       float64  `json:"ratio"`
+	PValue      *float64 `json:"p_value,omitempty"`
+	Significant bool     `json:"significant"`
+}
+
+// CompareStat is the result of `vigil compare`. Every entry in Comparisons
+// relates the command at the same index+1 to the first command.
+type CompareStat struct {
+	Runs        int               `json:"runs"`
+	Warmup      int               `json:"warmup"`
+	Alternate   bool              `json:"alternate"`
+	Commands    []BenchStat       `json:"commands"`
+	Comparisons []BenchComparison `json:"comparisons"`
+}
+
+// Baseline is a committed performance reference for `vigil exec --baseline`.
+// The embedded ExecStat holds the mean of Samples runs.
+type Baseline struct {
+	ExecStat
+	Samples            int     `json:"samples"`
+	ElapsedVariance    float64 `json:"elapsed_variance"`
+	CPUSecondsVariance float64 `json:"cpu_seconds_variance"`
+	RAMPeakVariance    float64 `json:"ram_peak_variance"`
+}
+
+// BudgetCheck is the result of comparing a run against a Baseline
+type BudgetCheck struct {
+	Baseline         string         `json:"baseline"`
+	TolerancePercent float64        `json:"tolerance_percent"`
+	Updated          bool           `json:"updated,omitempty"`
+	Regressed        bool           `json:"regressed"`
+	Metrics          []BudgetMetric `json:"metrics,omitempty"`
+}
+
+type BudgetMetric struct {
+	Name           string  `json:"name"`
+	Baseline       float64 `json:"baseline"`
+	BaselineStdDev float64 `json:"baseline_stddev"`
+	Current        float64 `json:"current"`
+	Limit          float64 `json:"limit"`
+	ChangePercent  float64 `json:"change_percent"`
+	Regressed      bool    `json:"regressed"`
+}
+
+// Snapshot is the one-pass host overview printed by `vigil status`. Load
+// is omitted where the OS has no load average. Errors lists the sources
+// that failed.
+type Snapshot struct {
+	Time          time.Time     `json:"timestamp"`
+	Hostname      string        `json:"hostname"`
+	UptimeSeconds uint64        `json:"uptime_seconds"`
+	CPU           CPUStat       `json:"cpu"`
+	Load          *LoadStat     `json:"load,omitempty"`
+	Mem           MemStat       `json:"mem"`
+	Swap          SwapStat      `json:"swap"`
+	Disks         []DiskStat    `json:"disks"`
+	Net           []NetRate     `json:"net"`
+	Processes     []ProcessStat `json:"top_processes"`
+	Errors        []SourceError `json:"errors,omitempty"`
+	Scope         string        `json:"scope,omitempty"`
+	Pids          *PidsStat     `json:"pids,omitempty"`
+	Sensors       *SensorsStat  `json:"sensors,omitempty"`
+}
+
+// PidsStat is the number of tasks in a cgroup; Limit is 0 when unlimited
+type PidsStat struct {
+	Current uint64 `json:"current"`
+	Limit   uint64 `json:"limit,omitempty"`
+}
+
+// SourceError records a source (cpu, memory, disk, ...) that could not be
+// read; the rest of the snapshot is still filled in
+type SourceError struct {
+	Source string `json:"source"`
+	Error  string `json:"error"`
+}
+
+type LoadStat struct {
+	Load1  float64 `json:"load1"`
+	Load5  float64 `json:"load5"`
+	Load15 float64 `json:"load15"`
+}
+
+type SwapStat struct {
 	TotalBytes  uint64  `json:"total_bytes"`
 	UsedBytes   uint64  `json:"used_bytes"`
-	FreeBytes   uint64  `json:"free_bytes"`
 	UsedPercent float64 `json:"used_percent"`
 }
 
-type ExecStat struct {
-	Command        string  `json:"command"`
-	ExitCode       int     `json:"exit_code"`
-	ElapsedSeconds float64 `json:"elapsed_seconds"`
-	CPUAvgPercent  float64 `json:"cpu_avg_percent"`
-	RAMPeakMB      float64 `json:"ram_peak_mb"`
-}
\ No newline at end of file
+// NetRate is the traffic of one interface over the snapshot interval
+type NetRate struct {
+	Interface     string  `json:"interface"`
+	RxBytesPerSec float64 `json:"rx_bytes_per_sec"`
+	TxBytesPerSec float64 `json:"tx_bytes_per_sec"`
+}
+
+type ProcessStat struct {
+	PID        int32   `json:"pid"`
+	Name       string  `json:"name"`
+	CPUPercent float64 `json:"cpu_percent"`
+	RSSBytes   uint64  `json:"rss_bytes"`
+}
+
+// ContainerStat is one container's resource use as reported by the Docker
+// or Podman Engine API. Counters are totals since the container started.
+type ContainerStat struct {
+	ID              string            `json:"id"`
+	Name            string            `json:"name"`
+	Image           string            `json:"image"`
+	State           string            `json:"state"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	CPUPercent      float64           `json:"cpu_percent"`
+	MemUsedBytes    uint64            `json:"mem_used_bytes"`
+	MemLimitBytes   uint64            `json:"mem_limit_bytes"`
+	MemPercent      float64           `json:"mem_percent"`
+	NetRxBytes      uint64            `json:"net_rx_bytes"`
+	NetTxBytes      uint64            `json:"net_tx_bytes"`
+	BlockReadBytes  uint64            `json:"block_read_bytes"`
+	BlockWriteBytes uint64            `json:"block_write_bytes"`
+	Pids            uint64            `json:"pids"`
+}
+
+// ContainerList is the output of `vigil containers`
+type ContainerList struct {
+	Time       time.Time       `json:"timestamp"`
+	Socket     string          `json:"socket"`
+	Containers []ContainerStat `json:"containers"`
+}
+
+// UnitStat is one systemd unit's resource use, read from its cgroup. CPU
+// and IO rates are measured over the reading interval; CPUPercent is of
+// one core, like top. Units without processes, such as failed ones, have
+// no readings.
+type UnitStat struct {
+	Name               string  `json:"name"`
+	Cgroup             string  `json:"cgroup,omitempty"`
+	Load               string  `json:"load,omitempty"`
+	Active             string  `json:"active,omitempty"`
+	Sub                string  `json:"sub,omitempty"`
+	Description        string  `json:"description,omitempty"`
+	CPUPercent         float64 `json:"cpu_percent"`
+	CPUSeconds         float64 `json:"cpu_seconds"`
+	MemBytes           uint64  `json:"mem_bytes"`
+	MemLimitBytes      uint64  `json:"mem_limit_bytes,omitempty"`
+	Tasks              uint64  `json:"tasks"`
+	IOReadBytes        uint64  `json:"io_read_bytes"`
+	IOWriteBytes       uint64  `json:"io_write_bytes"`
+	IOReadBytesPerSec  float64 `json:"io_read_bytes_per_sec"`
+	IOWriteBytesPerSec float64 `json:"io_write_bytes_per_sec"`
+}
+
+// UnitList is the output of `vigil units`
+type UnitList struct {
+	Time  time.Time  `json:"timestamp"`
+	Units []UnitStat `json:"units"`
+}
+
+// SensorsStat is the output of `vigil sensors`. Throttled lists the
+// throttling in effect now; ThrottledSinceBoot is only reported by the
+// Raspberry Pi firmware. ACOnline is nil without a mains power supply.
+type SensorsStat struct {
+	Time               time.Time     `json:"timestamp"`
+	Temperatures       []TempStat    `json:"temperatures"`
+	MaxCelsius         float64       `json:"max_celsius"`
+	Fans               []FanStat     `json:"fans"`
+	Throttled          []string      `json:"throttled"`
+	ThrottledSinceBoot []string      `json:"throttled_since_boot"`
+	Batteries          []BatteryStat `json:"batteries"`
+	ACOnline           *bool         `json:"ac_online,omitempty"`
+}
+
+// TempStat is one temperature sensor. HighCelsius (where the CPU is
+// throttled) and CriticalCelsius are 0 when the sensor has none.
+type TempStat struct {
+	Sensor          string  `json:"sensor"`
+	Celsius         float64 `json:"celsius"`
+	HighCelsius     float64 `json:"high_celsius,omitempty"`
+	CriticalCelsius float64 `json:"critical_celsius,omitempty"`
+}
+
+type FanStat struct {
+	Sensor string `json:"sensor"`
+	RPM    uint64 `json:"rpm"`
+}
+
+type BatteryStat struct {
+	Name    string  `json:"name"`
+	Percent float64 `json:"percent"`
+	Status  string  `json:"status"`
+}
+
+// DiskIOStat is the output of `vigil io`: per-device activity over
+// IntervalSeconds
+type DiskIOStat struct {
+	Time            time.Time      `json:"timestamp"`
+	IntervalSeconds float64        `json:"interval_seconds"`
+	Devices         []DeviceIOStat `json:"devices"`
+}
+
+// DeviceIOStat is one block device's activity. Latencies are the average
+// time per completed request; QueueDepth is the average number of requests
+// in flight and InFlight the number at the end of the interval.
+type DeviceIOStat struct {
+	Device           string  `json:"device"`
+	ReadIOPS         float64 `json:"read_iops"`
+	WriteIOPS        float64 `json:"write_iops"`
+	ReadBytesPerSec  float64 `json:"read_bytes_per_sec"`
+	WriteBytesPerSec float64 `json:"write_bytes_per_sec"`
+	ReadLatencyMs    float64 `json:"read_latency_ms"`
+	WriteLatencyMs   float64 `json:"write_latency_ms"`
+	QueueDepth       float64 `json:"queue_depth"`
+	InFlight         uint64  `json:"in_flight"`
+	UtilPercent      float64 `json:"util_percent"`
+	WeightedIOMs     uint64  `json:"weighted_io_ms"`
+}
+
+// DuStat is the output of `vigil du`: what takes up the space below Path,
+// ranked by allocated size, or apparent size when RankedBy says so. Disk
+// is the filesystem Path is on.
+type DuStat struct {
+	Path           string    `json:"path"`
+	ApparentBytes  uint64    `json:"apparent_bytes"`
+	AllocatedBytes uint64    `json:"allocated_bytes"`
+	Files          uint64    `json:"files"`
+	RankedBy       string    `json:"ranked_by"`
+	ElapsedSeconds float64   `json:"elapsed_seconds"`
+	Unreadable     int       `json:"unreadable,omitempty"`
+	Disk           *DiskStat `json:"disk,omitempty"`
+	Dirs           []DuEntry `json:"dirs"`
+	TopFiles       []DuEntry `json:"top_files"`
+}
+
+// DuEntry is a directory with everything below it, or a single file
+type DuEntry struct {
+	Path           string `json:"path"`
+	ApparentBytes  uint64 `json:"apparent_bytes"`
+	AllocatedBytes uint64 `json:"allocated_bytes"`
+	Files          uint64 `json:"files,omitempty"`
+}
+
+// duSize is an entry's size as its DuStat ranks it
+func duSize(s DuStat, e DuEntry) uint64 {
+	if s.RankedBy == "apparent" {
+		return e.ApparentBytes
+	}
+	return e.AllocatedBytes
+}
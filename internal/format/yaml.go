@@ -0,0 +1,44 @@
+// internal/format/yaml.go
+package format
+
+import (
+	"encoding/json"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlFormat renders stats as block YAML using the same field names as the
+// JSON output. The JSON document is parsed as YAML (JSON is a subset) so
+// field order is kept, then restyled from flow to block style.
+func yamlFormat(w io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	clearStyle(&doc)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func clearStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		clearStyle(c)
+	}
+}
+
+func init() {
+	Register("yaml", func(opts Options) (Formatter, error) {
+		return funcFormatter(yamlFormat), nil
+	})
+}
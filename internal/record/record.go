@@ -0,0 +1,158 @@
+// internal/record/record.go
+package record
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sahil3982/vigil/internal/format"
+)
+
+var csvHeader = []string{"time", "elapsed_seconds", "rss_bytes", "cpu_percent", "threads", "read_bytes", "write_bytes"}
+
+// Writer streams exec samples to a recording file
+type Writer interface {
+	Write(s format.ExecSample) error
+	Close() error
+}
+
+// Create opens a recording at path. Files ending in .csv are written as CSV,
+// everything else as NDJSON.
+func Create(path string) (Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if isCSV(path) {
+		w := csv.NewWriter(f)
+		if err := w.Write(csvHeader); err != nil {
+			f.Close()
+			return nil, err
+		}
+		return &csvWriter{f: f, w: w}, nil
+	}
+	return &ndjsonWriter{f: f, enc: json.NewEncoder(f)}, nil
+}
+
+type ndjsonWriter struct {
+	f   *os.File
+	enc *json.Encoder
+}
+
+func (n *ndjsonWriter) Write(s format.ExecSample) error {
+	return n.enc.Encode(s)
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.f.Close()
+}
+
+type csvWriter struct {
+	f *os.File
+	w *csv.Writer
+}
+
+func (c *csvWriter) Write(s format.ExecSample) error {
+	err := c.w.Write([]string{
+		s.Time.Format(time.RFC3339Nano),
+		strconv.FormatFloat(s.ElapsedSeconds, 'f', 3, 64),
+		strconv.FormatUint(s.RSSBytes, 10),
+		strconv.FormatFloat(s.CPUPercent, 'f', 2, 64),
+		strconv.FormatInt(int64(s.Threads), 10),
+		strconv.FormatUint(s.ReadBytes, 10),
+		strconv.FormatUint(s.WriteBytes, 10),
+	})
+	if err != nil {
+		return err
+	}
+	// Flush every row so a recording survives the command being killed
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		c.f.Close()
+		return err
+	}
+	return c.f.Close()
+}
+
+// Read loads all samples from a recording written by Create
+func Read(path string) ([]format.ExecSample, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if isCSV(path) {
+		return readCSV(f)
+	}
+	return readNDJSON(f)
+}
+
+func readNDJSON(r io.Reader) ([]format.ExecSample, error) {
+	var samples []format.ExecSample
+	sc := bufio.NewScanner(r)
+	line := 0
+	for sc.Scan() {
+		line++
+		if strings.TrimSpace(sc.Text()) == "" {
+			continue
+		}
+		var s format.ExecSample
+		if err := json.Unmarshal(sc.Bytes(), &s); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		samples = append(samples, s)
+	}
+	return samples, sc.Err()
+}
+
+func readCSV(r io.Reader) ([]format.ExecSample, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	var samples []format.ExecSample
+	for i, row := range rows[1:] {
+		if len(row) != len(csvHeader) {
+			return nil, fmt.Errorf("row %d: expected %d columns, got %d", i+2, len(csvHeader), len(row))
+		}
+		var s format.ExecSample
+		var errs [7]error
+		s.Time, errs[0] = time.Parse(time.RFC3339Nano, row[0])
+		s.ElapsedSeconds, errs[1] = strconv.ParseFloat(row[1], 64)
+		s.RSSBytes, errs[2] = strconv.ParseUint(row[2], 10, 64)
+		s.CPUPercent, errs[3] = strconv.ParseFloat(row[3], 64)
+		threads, terr := strconv.ParseInt(row[4], 10, 32)
+		s.Threads, errs[4] = int32(threads), terr
+		s.ReadBytes, errs[5] = strconv.ParseUint(row[5], 10, 64)
+		s.WriteBytes, errs[6] = strconv.ParseUint(row[6], 10, 64)
+		for col, err := range errs {
+			if err != nil {
+				return nil, fmt.Errorf("row %d, column %s: %w", i+2, csvHeader[col], err)
+			}
+		}
+		samples = append(samples, s)
+	}
+	return samples, nil
+}
+
+func isCSV(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".csv")
+}
@@ -0,0 +1,232 @@
+// internal/record/report.go
+package record
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/sahil3982/vigil/internal/format"
+)
+
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+// Series is one metric extracted from a recording
+type Series struct {
+	Name   string
+	Unit   string
+	Values []float64
+}
+
+func (s Series) Min() float64 {
+	if len(s.Values) == 0 {
+		return 0
+	}
+	min := s.Values[0]
+	for _, v := range s.Values[1:] {
+		min = math.Min(min, v)
+	}
+	return min
+}
+
+func (s Series) Max() float64 {
+	if len(s.Values) == 0 {
+		return 0
+	}
+	max := s.Values[0]
+	for _, v := range s.Values[1:] {
+		max = math.Max(max, v)
+	}
+	return max
+}
+
+func (s Series) Avg() float64 {
+	if len(s.Values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range s.Values {
+		sum += v
+	}
+	return sum / float64(len(s.Values))
+}
+
+// BuildSeries turns raw samples into plottable series. IO counters are
+// cumulative, so they are converted to per-second rates.
+func BuildSeries(samples []format.ExecSample) []Series {
+	rss := Series{Name: "RSS", Unit: "MB"}
+	cpu := Series{Name: "CPU", Unit: "%"}
+	threads := Series{Name: "Threads"}
+	read := Series{Name: "Read", Unit: "MB/s"}
+	write := Series{Name: "Write", Unit: "MB/s"}
+
+	for i, s := range samples {
+		rss.Values = append(rss.Values, float64(s.RSSBytes)/(1024*1024))
+		cpu.Values = append(cpu.Values, s.CPUPercent)
+		threads.Values = append(threads.Values, float64(s.Threads))
+
+		var r, wr float64
+		if i > 0 {
+			prev := samples[i-1]
+			dt := s.ElapsedSeconds - prev.ElapsedSeconds
+			if dt > 0 {
+				r = rate(prev.ReadBytes, s.ReadBytes, dt)
+				wr = rate(prev.WriteBytes, s.WriteBytes, dt)
+			}
+		}
+		read.Values = append(read.Values, r)
+		write.Values = append(write.Values, wr)
+	}
+	return []Series{rss, cpu, threads, read, write}
+}
+
+func rate(prev, curr uint64, seconds float64) float64 {
+	if curr < prev {
+		return 0
+	}
+	return float64(curr-prev) / (1024 * 1024) / seconds
+}
+
+// Sparkline renders values as a row of block characters, downsampling to
+// width by taking the max of each bucket so short spikes stay visible.
+func Sparkline(values []float64, width int) string {
+	if len(values) == 0 || width <= 0 {
+		return ""
+	}
+	buckets := downsample(values, width)
+
+	min, max := buckets[0], buckets[0]
+	for _, v := range buckets {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+
+	var b strings.Builder
+	for _, v := range buckets {
+		idx := 0
+		if max > min {
+			idx = int((v - min) / (max - min) * float64(len(sparkTicks)-1))
+		}
+		b.WriteRune(sparkTicks[idx])
+	}
+	return b.String()
+}
+
+func downsample(values []float64, width int) []float64 {
+	if len(values) <= width {
+		return values
+	}
+	out := make([]float64, width)
+	for i := range out {
+		lo := i * len(values) / width
+		hi := (i + 1) * len(values) / width
+		max := values[lo]
+		for _, v := range values[lo:hi] {
+			max = math.Max(max, v)
+		}
+		out[i] = max
+	}
+	return out
+}
+
+// WriteText prints an ASCII sparkline summary of a recording
+func WriteText(w io.Writer, title string, samples []format.ExecSample) error {
+	if len(samples) == 0 {
+		_, err := fmt.Fprintf(w, "%s: no samples\n", title)
+		return err
+	}
+	duration := samples[len(samples)-1].ElapsedSeconds
+	fmt.Fprintf(w, "▶ %s — %d samples over %.2fs\n", title, len(samples), duration)
+	for _, s := range BuildSeries(samples) {
+		_, err := fmt.Fprintf(w, "   %-8s %s  min %.1f  avg %.1f  max %.1f %s\n",
+			s.Name, Sparkline(s.Values, 40), s.Min(), s.Avg(), s.Max(), s.Unit)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const chartWidth, chartHeight = 800, 160
+
+type htmlChart struct {
+	Series
+	Points template.HTMLAttr
+}
+
+var htmlTmpl = template.Must(template.New("report").Funcs(template.FuncMap{
+	"f1": func(v float64) string { return fmt.Sprintf("%.1f", v) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<title>vigil report — {{.Title}}</title>
+<style>
+  body { background: #0d1117; color: #c9d1d9; font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 2rem; }
+  h1 { font-size: 1.3rem; }
+  .chart { background: #161b22; border: 1px solid #30363d; border-radius: 6px; padding: 1rem; margin-bottom: 1rem; }
+  .chart h2 { font-size: 1rem; margin: 0 0 .5rem; }
+  .stats { color: #8b949e; font-size: .85rem; }
+  svg { width: 100%; height: auto; }
+  polyline { fill: none; stroke: #58a6ff; stroke-width: 1.5; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="stats">{{.Count}} samples over {{f1 .Duration}}s</p>
+{{range .Charts}}
+<div class="chart">
+  <h2>{{.Name}}{{if .Unit}} ({{.Unit}}){{end}}</h2>
+  <svg viewBox="0 0 {{$.Width}} {{$.Height}}" preserveAspectRatio="none">
+    <polyline {{.Points}}/>
+  </svg>
+  <div class="stats">min {{f1 .Min}} · avg {{f1 .Avg}} · max {{f1 .Max}}</div>
+</div>
+{{end}}
+</body>
+</html>
+`))
+
+// WriteHTML renders a self-contained HTML page with one SVG chart per series
+func WriteHTML(w io.Writer, title string, samples []format.ExecSample) error {
+	var charts []htmlChart
+	for _, s := range BuildSeries(samples) {
+		charts = append(charts, htmlChart{Series: s, Points: svgPoints(s.Values)})
+	}
+	duration := 0.0
+	if len(samples) > 0 {
+		duration = samples[len(samples)-1].ElapsedSeconds
+	}
+	return htmlTmpl.Execute(w, map[string]interface{}{
+		"Title":    title,
+		"Count":    len(samples),
+		"Duration": duration,
+		"Charts":   charts,
+		"Width":    chartWidth,
+		"Height":   chartHeight,
+	})
+}
+
+func svgPoints(values []float64) template.HTMLAttr {
+	max := 0.0
+	for _, v := range values {
+		max = math.Max(max, v)
+	}
+	var b strings.Builder
+	b.WriteString(`points="`)
+	for i, v := range values {
+		x := 0.0
+		if len(values) > 1 {
+			x = float64(i) / float64(len(values)-1) * chartWidth
+		}
+		y := float64(chartHeight)
+		if max > 0 {
+			y -= v / max * (chartHeight - 4)
+		}
+		fmt.Fprintf(&b, "%.1f,%.1f ", x, y)
+	}
+	b.WriteString(`"`)
+	return template.HTMLAttr(b.String())
+}
@@ -0,0 +1,308 @@
+// internal/sensors/sensors.go
+package sensors
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/common"
+	"github.com/shirou/gopsutil/v3/host"
+)
+
+// Temperature is one sensor in degrees Celsius. High and Critical are 0
+// when the sensor has no such threshold.
+type Temperature struct {
+	Sensor   string
+	Celsius  float64
+	High     float64
+	Critical float64
+}
+
+// Fan is one fan's speed
+type Fan struct {
+	Sensor string
+	RPM    uint64
+}
+
+// Battery is one battery's charge. Status is the kernel's: Charging,
+// Discharging, Full, Not charging or Unknown.
+type Battery struct {
+	Name    string
+	Percent float64
+	Status  string
+}
+
+// Reading is everything read from the hardware sensors. Throttled holds
+// the throttling in effect now, ThrottledSinceBoot what happened earlier
+// (only the Raspberry Pi firmware tracks that). ACOnline is nil without a
+// mains power supply.
+type Reading struct {
+	Temperatures       []Temperature
+	Fans               []Fan
+	Throttled          []string
+	ThrottledSinceBoot []string
+	Batteries          []Battery
+	ACOnline           *bool
+}
+
+// Empty reports whether no sensor at all was found
+func (r Reading) Empty() bool {
+	return len(r.Temperatures) == 0 && len(r.Fans) == 0 && len(r.Batteries) == 0 &&
+		r.ACOnline == nil && len(r.Throttled) == 0 && len(r.ThrottledSinceBoot) == 0
+}
+
+// Root is where sysfs is mounted: $HOST_SYS, as for gopsutil, or /sys
+func Root() string {
+	if root := os.Getenv("HOST_SYS"); root != "" {
+		return root
+	}
+	return "/sys"
+}
+
+// Read collects every sensor under the sysfs root. Missing sensors are
+// not an error; most servers and VMs have none.
+func Read(ctx context.Context, root string) (Reading, error) {
+	var r Reading
+	var err error
+	if r.Temperatures, err = Temperatures(ctx, root); err != nil {
+		return r, err
+	}
+	if r.Fans, err = Fans(root); err != nil {
+		return r, err
+	}
+	if r.Throttled, r.ThrottledSinceBoot, err = Throttling(root); err != nil {
+		return r, err
+	}
+	if r.Batteries, r.ACOnline, err = PowerSupplies(root); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+// Temperatures reads the thermal zones, which carry the trip points the
+// kernel throttles at, then the hwmon sensors through gopsutil. A hwmon
+// sensor with the same name as a zone (acpitz) is the same one.
+func Temperatures(ctx context.Context, root string) ([]Temperature, error) {
+	temps, err := ThermalZones(root)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for _, t := range temps {
+		seen[t.Sensor] = true
+	}
+
+	// gopsutil reports sensors it could not read as warnings alongside
+	// the ones it could
+	ctx = context.WithValue(ctx, common.EnvKey, common.EnvMap{common.HostSysEnvKey: root})
+	hw, err := host.SensorsTemperaturesWithContext(ctx)
+	var warnings *host.Warnings
+	if err != nil && !errors.As(err, &warnings) {
+		return temps, err
+	}
+	for _, t := range hw {
+		if seen[t.SensorKey] {
+			continue
+		}
+		seen[t.SensorKey] = true
+		temps = append(temps, Temperature{Sensor: t.SensorKey, Celsius: t.Temperature, High: t.High, Critical: t.Critical})
+	}
+	return temps, nil
+}
+
+// ThermalZones reads /sys/class/thermal/thermal_zone*. High is the lowest
+// passive trip point, where the kernel starts throttling the CPU.
+func ThermalZones(root string) ([]Temperature, error) {
+	zones, err := filepath.Glob(filepath.Join(root, "class/thermal/thermal_zone*"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(zones, func(i, j int) bool { return zoneNumber(zones[i]) < zoneNumber(zones[j]) })
+
+	temps := []Temperature{}
+	for _, zone := range zones {
+		milli, err := readInt(filepath.Join(zone, "temp"))
+		if err != nil {
+			// Disabled zones fail to read with ENODATA or EAGAIN
+			continue
+		}
+		t := Temperature{Sensor: readString(filepath.Join(zone, "type")), Celsius: float64(milli) / 1000}
+		if t.Sensor == "" {
+			t.Sensor = filepath.Base(zone)
+		}
+		trips, _ := filepath.Glob(filepath.Join(zone, "trip_point_*_type"))
+		for _, trip := range trips {
+			milli, err := readInt(strings.TrimSuffix(trip, "_type") + "_temp")
+			if err != nil || milli <= 0 {
+				continue
+			}
+			c := float64(milli) / 1000
+			switch readString(trip) {
+			case "passive":
+				if t.High == 0 || c < t.High {
+					t.High = c
+				}
+			case "critical":
+				t.Critical = c
+			}
+		}
+		temps = append(temps, t)
+	}
+	return temps, nil
+}
+
+func zoneNumber(path string) int {
+	n, _ := strconv.Atoi(strings.TrimPrefix(filepath.Base(path), "thermal_zone"))
+	return n
+}
+
+// Fans reads the hwmon fan*_input sensors
+func Fans(root string) ([]Fan, error) {
+	inputs, err := filepath.Glob(filepath.Join(root, "class/hwmon/hwmon*/fan*_input"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(inputs)
+	fans := []Fan{}
+	for _, input := range inputs {
+		rpm, err := readInt(input)
+		if err != nil || rpm < 0 {
+			continue
+		}
+		dir := filepath.Dir(input)
+		name := readString(filepath.Join(dir, "name"))
+		if label := readString(strings.TrimSuffix(input, "_input") + "_label"); label != "" {
+			name += "_" + strings.ReplaceAll(strings.ToLower(label), " ", "_")
+		} else {
+			name += "_" + strings.TrimSuffix(filepath.Base(input), "_input")
+		}
+		fans = append(fans, Fan{Sensor: name, RPM: uint64(rpm)})
+	}
+	return fans, nil
+}
+
+// piThrottled is the Raspberry Pi firmware's throttling bitmask; the same
+// bits shifted by 16 mean "has happened since boot"
+var piThrottled = []struct {
+	bit  uint
+	flag string
+}{
+	{0, "under-voltage"},
+	{1, "arm frequency capped"},
+	{2, "throttled"},
+	{3, "soft temperature limit"},
+}
+
+// Throttling returns the throttling in effect and what happened since
+// boot. It reads the Raspberry Pi firmware flags (what `vcgencmd
+// get_throttled` prints) and the CPU frequency cooling devices the kernel
+// steps up when a thermal zone passes its trip point.
+func Throttling(root string) (now, sinceBoot []string, err error) {
+	now, sinceBoot = []string{}, []string{}
+	raw := readString(filepath.Join(root, "devices/platform/soc/soc:firmware/get_throttled"))
+	if raw != "" {
+		mask, err := strconv.ParseUint(strings.TrimPrefix(raw, "0x"), 16, 32)
+		if err == nil {
+			for _, f := range piThrottled {
+				if mask&(1<<f.bit) != 0 {
+					now = append(now, f.flag)
+				}
+				if mask&(1<<(f.bit+16)) != 0 {
+					sinceBoot = append(sinceBoot, f.flag)
+				}
+			}
+		}
+	}
+
+	devices, err := filepath.Glob(filepath.Join(root, "class/thermal/cooling_device*"))
+	if err != nil {
+		return now, sinceBoot, err
+	}
+	sort.Strings(devices)
+	for _, dev := range devices {
+		kind := readString(filepath.Join(dev, "type"))
+		// Fans are cooling devices too, but a running fan is not throttling
+		if kind != "Processor" && !strings.HasPrefix(kind, "cpufreq") {
+			continue
+		}
+		cur, err := readInt(filepath.Join(dev, "cur_state"))
+		if err != nil || cur <= 0 {
+			continue
+		}
+		limit, _ := readInt(filepath.Join(dev, "max_state"))
+		now = append(now, kind+" cooling "+strconv.FormatInt(cur, 10)+"/"+strconv.FormatInt(limit, 10))
+	}
+	return now, sinceBoot, nil
+}
+
+// PowerSupplies reads /sys/class/power_supply. Device batteries such as
+// a wireless mouse's (scope "Device") are left out.
+func PowerSupplies(root string) (batteries []Battery, acOnline *bool, err error) {
+	supplies, err := filepath.Glob(filepath.Join(root, "class/power_supply/*"))
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(supplies)
+	batteries = []Battery{}
+	for _, dir := range supplies {
+		switch readString(filepath.Join(dir, "type")) {
+		case "Mains":
+			online := readString(filepath.Join(dir, "online")) == "1"
+			if acOnline == nil || online {
+				acOnline = &online
+			}
+		case "Battery":
+			if readString(filepath.Join(dir, "scope")) == "Device" {
+				continue
+			}
+			b := Battery{Name: filepath.Base(dir), Status: readString(filepath.Join(dir, "status"))}
+			if capacity, err := readInt(filepath.Join(dir, "capacity")); err == nil {
+				b.Percent = float64(capacity)
+			} else if now, full := chargeOf(dir); full > 0 {
+				b.Percent = float64(now) / float64(full) * 100
+			} else {
+				continue
+			}
+			// Worn batteries and some firmware report more than a full charge
+			b.Percent = min(b.Percent, 100)
+			batteries = append(batteries, b)
+		}
+	}
+	return batteries, acOnline, nil
+}
+
+// chargeOf reads the current and full charge of batteries that do not
+// report capacity, in µWh or µAh depending on the driver
+func chargeOf(dir string) (now, full int64) {
+	for _, prefix := range []string{"energy", "charge"} {
+		n, err1 := readInt(filepath.Join(dir, prefix+"_now"))
+		f, err2 := readInt(filepath.Join(dir, prefix+"_full"))
+		if err1 == nil && err2 == nil {
+			return n, f
+		}
+	}
+	return 0, 0
+}
+
+// readString returns a sysfs attribute without its newline, or "" if it
+// cannot be read
+func readString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func readInt(path string) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+}
@@ -0,0 +1,128 @@
+// internal/sensors/sensors_test.go
+package sensors
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const fixture = "testdata/sys"
+
+func TestThermalZones(t *testing.T) {
+	got, err := ThermalZones(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Temperature{
+		// The lowest passive trip point is where throttling starts
+		{Sensor: "x86_pkg_temp", Celsius: 65, High: 85, Critical: 105},
+		// A trip point of 0 is unset
+		{Sensor: "acpitz", Celsius: -5},
+		// zone2 has no temp; zone10 has no type and sorts after zone1
+		{Sensor: "thermal_zone10", Celsius: 41.5},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestThrottling(t *testing.T) {
+	tests := []struct {
+		name           string
+		getThrottled   string
+		now, sinceBoot []string
+	}{
+		{"cooling devices only", "", []string{"Processor cooling 3/10"}, []string{}},
+		{"pi clear", "0x0", []string{"Processor cooling 3/10"}, []string{}},
+		{
+			"pi under-voltage now and since boot", "0x50005",
+			[]string{"under-voltage", "throttled", "Processor cooling 3/10"},
+			[]string{"under-voltage", "throttled"},
+		},
+		{"pi capped earlier", "0x20000", []string{"Processor cooling 3/10"}, []string{"arm frequency capped"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			if err := os.Symlink(filepath.Join(mustAbs(t, fixture), "class"), filepath.Join(root, "class")); err != nil {
+				t.Fatal(err)
+			}
+			if tt.getThrottled != "" {
+				// The firmware's directory has a colon in it, which module
+				// paths cannot hold, so it is not in testdata
+				dir := filepath.Join(root, "devices/platform/soc/soc:firmware")
+				if err := os.MkdirAll(dir, 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, "get_throttled"), []byte(tt.getThrottled+"\n"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			now, sinceBoot, err := Throttling(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(now, tt.now) || !reflect.DeepEqual(sinceBoot, tt.sinceBoot) {
+				t.Errorf("got %q %q, want %q %q", now, sinceBoot, tt.now, tt.sinceBoot)
+			}
+		})
+	}
+}
+
+func TestPowerSupplies(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		name      string
+		root      string
+		batteries []Battery
+		acOnline  *bool
+	}{
+		{
+			"laptop", fixture,
+			[]Battery{
+				{Name: "BAT0", Percent: 87, Status: "Discharging"},
+				// No capacity file: energy_now over energy_full
+				{Name: "BAT1", Percent: 75, Status: "Charging"},
+				// BAT2 reports no charge, hidpp_battery_0 is a mouse
+				// A worn battery can read above its full charge
+				{Name: "BAT3", Percent: 100, Status: "Full"},
+			},
+			// One adapter online is enough
+			&yes,
+		},
+		{"desktop", "testdata/desktop", []Battery{}, &no},
+		{"no supplies", t.TempDir(), []Battery{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batteries, acOnline, err := PowerSupplies(tt.root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(batteries, tt.batteries) {
+				t.Errorf("batteries: got %+v, want %+v", batteries, tt.batteries)
+			}
+			if !reflect.DeepEqual(acOnline, tt.acOnline) {
+				t.Errorf("AC online: got %v, want %v", deref(acOnline), deref(tt.acOnline))
+			}
+		})
+	}
+}
+
+func mustAbs(t *testing.T, path string) string {
+	t.Helper()
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return abs
+}
+
+func deref(b *bool) any {
+	if b == nil {
+		return nil
+	}
+	return *b
+}
@@ -0,0 +1,178 @@
+// internal/stats/stats.go
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+func Mean(xs []float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, x := range xs {
+		sum += x
+	}
+	return sum / float64(len(xs))
+}
+
+func Median(xs []float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	s := append([]float64(nil), xs...)
+	sort.Float64s(s)
+	mid := len(s) / 2
+	if len(s)%2 == 0 {
+		return (s[mid-1] + s[mid]) / 2
+	}
+	return s[mid]
+}
+
+// Variance is the sample variance (n-1 denominator)
+func Variance(xs []float64) float64 {
+	if len(xs) < 2 {
+		return 0
+	}
+	m := Mean(xs)
+	sum := 0.0
+	for _, x := range xs {
+		sum += (x - m) * (x - m)
+	}
+	return sum / float64(len(xs)-1)
+}
+
+func StdDev(xs []float64) float64 {
+	return math.Sqrt(Variance(xs))
+}
+
+func Min(xs []float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	min := xs[0]
+	for _, x := range xs[1:] {
+		min = math.Min(min, x)
+	}
+	return min
+}
+
+func Max(xs []float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	max := xs[0]
+	for _, x := range xs[1:] {
+		max = math.Max(max, x)
+	}
+	return max
+}
+
+// Outliers returns the indexes of values whose modified Z-score exceeds 3.5
+// (Iglewicz and Hoaglin), which is robust against the outliers themselves.
+func Outliers(xs []float64) []int {
+	if len(xs) < 3 {
+		return nil
+	}
+	med := Median(xs)
+	dev := make([]float64, len(xs))
+	for i, x := range xs {
+		dev[i] = math.Abs(x - med)
+	}
+	mad := Median(dev)
+	if mad == 0 {
+		return nil
+	}
+
+	var out []int
+	for i, x := range xs {
+		if 0.6745*math.Abs(x-med)/mad > 3.5 {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// WelchTTest returns the two-tailed p-value for the hypothesis that a and b
+// have the same mean, without assuming equal variances. ok is false when
+// either side has fewer than two values.
+func WelchTTest(a, b []float64) (p float64, ok bool) {
+	if len(a) < 2 || len(b) < 2 {
+		return 0, false
+	}
+	va := Variance(a) / float64(len(a))
+	vb := Variance(b) / float64(len(b))
+	diff := Mean(a) - Mean(b)
+	if va+vb == 0 {
+		if diff == 0 {
+			return 1, true
+		}
+		return 0, true
+	}
+
+	t := diff / math.Sqrt(va+vb)
+	df := (va + vb) * (va + vb) / (va*va/float64(len(a)-1) + vb*vb/float64(len(b)-1))
+	return regIncBeta(df/2, 0.5, df/(df+t*t)), true
+}
+
+// regIncBeta is the regularized incomplete beta function I_x(a, b),
+// evaluated with the continued fraction from Numerical Recipes.
+func regIncBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+
+	if x > (a+1)/(a+b+2) {
+		return 1 - front*betaCF(b, a, 1-x)/b
+	}
+	return front * betaCF(a, b, x) / a
+}
+
+func betaCF(a, b, x float64) float64 {
+	const eps, tiny = 1e-12, 1e-300
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1.0; m <= 200; m++ {
+		m2 := 2 * m
+		aa := m * (b - m) * x / ((a + m2 - 1) * (a + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+
+		aa = -(a + m) * (a + b + m) * x / ((a + m2) * (a + m2 + 1))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < eps {
+			break
+		}
+	}
+	return h
+}
@@ -0,0 +1,122 @@
+// internal/stats/stats_test.go
+package stats
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestWelchTTest(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float64
+		p    float64
+		ok   bool
+	}{
+		// The expected p-values integrate the Student t density at the
+		// Welch-Satterthwaite degrees of freedom, independently of the
+		// continued fraction
+		{
+			// Wikipedia's Welch's t-test example 1: t = -2.46, df = 24.99
+			"equal sizes",
+			[]float64{27.5, 21.0, 19.0, 23.6, 17.0, 17.9, 16.9, 20.1, 21.9, 22.6, 23.1, 19.6, 19.0, 21.7, 21.4},
+			[]float64{27.1, 22.0, 20.8, 23.4, 23.4, 23.5, 25.8, 22.0, 24.8, 20.2, 21.9, 22.1, 22.9, 20.5, 24.4},
+			0.0213779988, true,
+		},
+		{
+			// df = 9.90, far from the pooled 28
+			"unequal sizes and variances",
+			[]float64{17.2, 20.9, 22.6, 18.1, 21.7, 21.4, 23.5, 24.2, 14.7, 21.8},
+			[]float64{21.5, 22.8, 21.0, 23.0, 21.6, 23.6, 22.5, 20.7, 23.4, 21.8, 20.7, 21.7, 21.5, 22.5, 23.6, 21.5, 22.5, 23.5, 21.5, 21.8},
+			0.1488416937, true,
+		},
+		{"clear regression", []float64{1.02, 0.98, 1.01, 0.99, 1.00}, []float64{1.10, 1.12, 1.09, 1.11, 1.13}, 4.14884420e-6, true},
+		{"no difference", []float64{10.1, 10.3, 9.9, 10.0, 10.2, 10.1}, []float64{10.2, 10.0, 10.4, 10.1, 10.3, 10.2, 10.0}, 0.3958997019, true},
+		// df = 1.47, below 2
+		{"two values each", []float64{1, 2}, []float64{3, 5}, 0.1987273889, true},
+		{"identical samples", []float64{3, 4, 5}, []float64{3, 4, 5}, 1, true},
+		{"zero variance, same mean", []float64{2, 2, 2}, []float64{2, 2}, 1, true},
+		{"zero variance, different means", []float64{2, 2, 2}, []float64{3, 3}, 0, true},
+		{"one value", []float64{1}, []float64{1, 2, 3}, 0, false},
+		{"empty", nil, []float64{1, 2}, 0, false},
+	}
+	for _, tt := range tests {
+		p, ok := WelchTTest(tt.a, tt.b)
+		if ok != tt.ok || math.Abs(p-tt.p) > 1e-6*math.Max(tt.p, 1e-3) {
+			t.Errorf("%s: got %v, %v, want %v, %v", tt.name, p, ok, tt.p, tt.ok)
+		}
+		// The test is symmetric
+		if q, _ := WelchTTest(tt.b, tt.a); q != p {
+			t.Errorf("%s: swapped samples give %v, want %v", tt.name, q, p)
+		}
+	}
+}
+
+func TestRegIncBeta(t *testing.T) {
+	tests := []struct {
+		a, b, x float64
+		want    float64
+	}{
+		// I_x(a, 1) = x^a and I_x(1, b) = 1 - (1-x)^b, on both sides of
+		// the (a+1)/(a+b+2) switch to the symmetric form
+		{2.5, 1, 0.3, math.Pow(0.3, 2.5)},
+		{2.5, 1, 0.9, math.Pow(0.9, 2.5)},
+		{1, 3.5, 0.1, 1 - math.Pow(0.9, 3.5)},
+		{1, 3.5, 0.6, 1 - math.Pow(0.4, 3.5)},
+		// I_x(1/2, 1/2) is the arcsine distribution
+		{0.5, 0.5, 0.25, 2 / math.Pi * math.Asin(0.5)},
+		// Symmetric around 1/2
+		{7, 7, 0.5, 0.5},
+		// The t distribution with 1 and 2 degrees of freedom: p for t = 3
+		// and t = 1.5
+		{0.5, 0.5, 1.0 / 10, 1 - 2/math.Pi*math.Atan(3)},
+		{1, 0.5, 2 / 4.25, 1 - 1.5/math.Sqrt(4.25)},
+		{3, 2, 0, 0},
+		{3, 2, -0.5, 0},
+		{3, 2, 1, 1},
+		{3, 2, 1.5, 1},
+	}
+	for _, tt := range tests {
+		if got := regIncBeta(tt.a, tt.b, tt.x); math.Abs(got-tt.want) > 1e-10 {
+			t.Errorf("I_%v(%v, %v): got %v, want %v", tt.x, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestBetaCF(t *testing.T) {
+	// With a = 1, I_x(1, b) = 1 - (1-x)^b pins the continued fraction to
+	// (1 - (1-x)^b) / (b x (1-x)^b)
+	for _, tt := range []struct{ b, x float64 }{{1, 0.2}, {2, 0.1}, {4.5, 0.05}, {10, 0.08}} {
+		q := math.Pow(1-tt.x, tt.b)
+		want := (1 - q) / (tt.b * tt.x * q)
+		if got := betaCF(1, tt.b, tt.x); math.Abs(got-want) > 1e-10*want {
+			t.Errorf("betaCF(1, %v, %v): got %v, want %v", tt.b, tt.x, got, want)
+		}
+	}
+}
+
+func TestOutliers(t *testing.T) {
+	tests := []struct {
+		name string
+		xs   []float64
+		want []int
+	}{
+		// Modified Z-score 89.7
+		{"one slow run", []float64{10, 10.1, 9.9, 10.2, 9.8, 30}, []int{5}},
+		// 22.0 and 31.5: the outliers do not hide each other
+		{"both ends", []float64{1, 50, 52, 49, 51, 48, 50, 120}, []int{0, 7}},
+		// 4.72 and 2.92 against the 3.5 threshold
+		{"just above", []float64{100, 101, 102, 103, 104, 113}, []int{5}},
+		{"just below", []float64{100, 101, 102, 103, 104, 109}, nil},
+		{"evenly spread", []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, nil},
+		// The median absolute deviation is 0
+		{"mostly identical", []float64{5, 5, 5, 5, 9}, nil},
+		{"too few", []float64{1, 100}, nil},
+	}
+	for _, tt := range tests {
+		if got := Outliers(tt.xs); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
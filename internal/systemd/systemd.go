@@ -0,0 +1,143 @@
+// internal/systemd/systemd.go
+package systemd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// unitSuffixes are the unit types that own processes and so a cgroup.
+// Slices only group other units.
+var unitSuffixes = []string{".service", ".scope", ".socket", ".mount", ".swap"}
+
+// Unit is a loaded unit as listed by systemctl
+type Unit struct {
+	Name        string
+	Load        string
+	Active      string
+	Sub         string
+	Description string
+}
+
+// Failed reports whether the unit is in the failed state
+func (u Unit) Failed() bool {
+	return u.Active == "failed"
+}
+
+// Running reports whether this host was booted with systemd, the same
+// check as sd_booted(3)
+func Running() bool {
+	info, err := os.Stat("/run/systemd/system")
+	return err == nil && info.IsDir()
+}
+
+// ListUnits returns every loaded service, scope, socket, mount and swap
+// unit, including inactive and failed ones
+func ListUnits(ctx context.Context) ([]Unit, error) {
+	out, err := exec.CommandContext(ctx, "systemctl", "list-units", "--all", "--plain", "--full",
+		"--no-legend", "--no-pager", "--type=service,scope,socket,mount,swap").Output()
+	if err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) && len(exit.Stderr) > 0 {
+			return nil, fmt.Errorf("systemctl: %s", strings.TrimSpace(string(exit.Stderr)))
+		}
+		return nil, fmt.Errorf("systemctl: %w", err)
+	}
+	return parseUnits(strings.NewReader(string(out)))
+}
+
+// parseUnits reads "UNIT LOAD ACTIVE SUB DESCRIPTION" lines. Some
+// systemd versions mark failed units with a leading "●" even with --plain.
+func parseUnits(r io.Reader) ([]Unit, error) {
+	var units []Unit
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 0 && (fields[0] == "●" || fields[0] == "*") {
+			fields = fields[1:]
+		}
+		if len(fields) < 4 {
+			continue
+		}
+		units = append(units, Unit{
+			Name:        fields[0],
+			Load:        fields[1],
+			Active:      fields[2],
+			Sub:         fields[3],
+			Description: strings.Join(fields[4:], " "),
+		})
+	}
+	return units, scanner.Err()
+}
+
+// UnitOf returns the unit that owns a cgroup path and the unit's own
+// cgroup, which may be a parent of path. Units of a user's service
+// manager live below user@<uid>.service and are returned instead of it.
+func UnitOf(cgroupPath string) (unit, unitPath string, ok bool) {
+	parts := strings.Split(strings.Trim(cgroupPath, "/"), "/")
+	for i := len(parts) - 1; i >= 0; i-- {
+		for _, suffix := range unitSuffixes {
+			if strings.HasSuffix(parts[i], suffix) {
+				return parts[i], "/" + path.Join(parts[:i+1]...), true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// ProcessCgroups maps every unit cgroup path to the PIDs in it, reading
+// /proc/<pid>/cgroup under procRoot. The systemd hierarchy is the unified
+// one on v2 and the named "name=systemd" one on v1 and hybrid hosts.
+// Processes that exit while being read are skipped.
+func ProcessCgroups(procRoot string) (map[string][]int32, error) {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil, err
+	}
+	units := map[string][]int32{}
+	for _, e := range entries {
+		pid, err := strconv.ParseInt(e.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(procRoot, e.Name(), "cgroup"))
+		if err != nil {
+			if errors.Is(err, os.ErrPermission) {
+				return nil, err
+			}
+			continue
+		}
+		if _, unitPath, ok := UnitOf(systemdPath(string(data))); ok {
+			units[unitPath] = append(units[unitPath], int32(pid))
+		}
+	}
+	return units, nil
+}
+
+// systemdPath picks the path of the systemd hierarchy from the contents
+// of a /proc/<pid>/cgroup file
+func systemdPath(procCgroup string) string {
+	unified := ""
+	for _, line := range strings.Split(procCgroup, "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		switch {
+		case parts[1] == "name=systemd":
+			return parts[2]
+		case parts[0] == "0" && parts[1] == "":
+			unified = parts[2]
+		}
+	}
+	return unified
+}